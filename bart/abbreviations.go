@@ -0,0 +1,214 @@
+package bart
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A Station is an entry in the built-in registry of BART stations. See the
+// official list of abbreviations at
+// https://api.bart.gov/docs/overview/abbrev.aspx.
+type Station struct {
+	Abbr string
+	Name string
+}
+
+var stationRegistry = []Station{
+	{Abbr: "12th", Name: "12th St. Oakland City Center"},
+	{Abbr: "16th", Name: "16th St. Mission"},
+	{Abbr: "19th", Name: "19th St. Oakland"},
+	{Abbr: "24th", Name: "24th St. Mission"},
+	{Abbr: "antc", Name: "Antioch"},
+	{Abbr: "ashb", Name: "Ashby"},
+	{Abbr: "balb", Name: "Balboa Park"},
+	{Abbr: "bayf", Name: "Bay Fair"},
+	{Abbr: "bery", Name: "Berryessa/North San Jose"},
+	{Abbr: "cast", Name: "Castro Valley"},
+	{Abbr: "civc", Name: "Civic Center/UN Plaza"},
+	{Abbr: "colm", Name: "Colma"},
+	{Abbr: "cols", Name: "Coliseum"},
+	{Abbr: "conc", Name: "Concord"},
+	{Abbr: "daly", Name: "Daly City"},
+	{Abbr: "dbrk", Name: "Downtown Berkeley"},
+	{Abbr: "deln", Name: "El Cerrito del Norte"},
+	{Abbr: "dubl", Name: "Dublin/Pleasanton"},
+	{Abbr: "embr", Name: "Embarcadero"},
+	{Abbr: "frmt", Name: "Fremont"},
+	{Abbr: "ftvl", Name: "Fruitvale"},
+	{Abbr: "glen", Name: "Glen Park"},
+	{Abbr: "hayw", Name: "Hayward"},
+	{Abbr: "lafy", Name: "Lafayette"},
+	{Abbr: "lake", Name: "Lake Merritt"},
+	{Abbr: "mcar", Name: "MacArthur"},
+	{Abbr: "mlbr", Name: "Millbrae"},
+	{Abbr: "mlpt", Name: "Milpitas"},
+	{Abbr: "mont", Name: "Montgomery St."},
+	{Abbr: "nbrk", Name: "North Berkeley"},
+	{Abbr: "ncon", Name: "North Concord/Martinez"},
+	{Abbr: "oakl", Name: "Oakland International Airport"},
+	{Abbr: "orin", Name: "Orinda"},
+	{Abbr: "pctr", Name: "Pittsburg Center"},
+	{Abbr: "phil", Name: "Pleasant Hill/Contra Costa Centre"},
+	{Abbr: "pitt", Name: "Pittsburg/Bay Point"},
+	{Abbr: "plza", Name: "El Cerrito Plaza"},
+	{Abbr: "powl", Name: "Powell St."},
+	{Abbr: "rich", Name: "Richmond"},
+	{Abbr: "rock", Name: "Rockridge"},
+	{Abbr: "sanl", Name: "San Leandro"},
+	{Abbr: "sbrn", Name: "San Bruno"},
+	{Abbr: "sfia", Name: "San Francisco International Airport"},
+	{Abbr: "shay", Name: "South Hayward"},
+	{Abbr: "ssan", Name: "South San Francisco"},
+	{Abbr: "ucty", Name: "Union City"},
+	{Abbr: "warm", Name: "Warm Springs/South Fremont"},
+	{Abbr: "wcrk", Name: "Walnut Creek"},
+	{Abbr: "wdub", Name: "West Dublin/Pleasanton"},
+	{Abbr: "woak", Name: "West Oakland"},
+}
+
+var stationsByAbbr = func() map[string]Station {
+	out := make(map[string]Station, len(stationRegistry))
+	for _, stn := range stationRegistry {
+		out[stn.Abbr] = stn
+	}
+	return out
+}()
+
+// Stations lists every station in the built-in registry, sorted by
+// abbreviation. Abbreviations are lower case.
+func Stations() []Station {
+	out := make([]Station, len(stationRegistry))
+	copy(out, stationRegistry)
+	return out
+}
+
+// LookupStation finds a station in the built-in registry by its 4-letter
+// abbreviation, which may be upper, lower or mixed case. If there is no such
+// station, then the error is an *InvalidStationError.
+func LookupStation(abbr string) (Station, error) {
+	return lookupStation("", abbr)
+}
+
+func lookupStation(param, abbr string) (Station, error) {
+	stn, ok := stationsByAbbr[strings.ToLower(strings.TrimSpace(abbr))]
+	if !ok {
+		return Station{}, newInvalidStationError(param, abbr)
+	}
+	return stn, nil
+}
+
+// InvalidStationError is returned when a station abbreviation is not in the
+// built-in registry. When it is returned by a request method, no request was
+// made. Errors for the orig and dest parameters match ErrInvalidOrig and
+// ErrInvalidDest, respectively, when using errors.Is.
+type InvalidStationError struct {
+	// Param is the name of the parameter, such as "orig" or "dest". It's empty
+	// if the error is not about a request parameter.
+	Param string
+	// Value is the input value.
+	Value string
+	// Suggestions is a list of abbreviations that are close to Value.
+	Suggestions []string
+}
+
+func newInvalidStationError(param, value string) *InvalidStationError {
+	return &InvalidStationError{
+		Param:       param,
+		Value:       value,
+		Suggestions: suggestStations(value),
+	}
+}
+
+func (e *InvalidStationError) Error() string {
+	msg := fmt.Sprintf("invalid station abbreviation %q", e.Value)
+	if e.Param != "" {
+		msg = fmt.Sprintf("%s for %s", msg, e.Param)
+	}
+	if len(e.Suggestions) > 0 {
+		msg = fmt.Sprintf("%s; did you mean %s?", msg, strings.Join(e.Suggestions, ", "))
+	}
+	return msg
+}
+
+func (e *InvalidStationError) Is(target error) bool {
+	switch target {
+	case ErrInvalidOrig:
+		return e.Param == "orig"
+	case ErrInvalidDest:
+		return e.Param == "dest"
+	default:
+		return false
+	}
+}
+
+// normalizeStation validates a station abbreviation for the request parameter,
+// param, and converts it to the case used by the registry.
+func normalizeStation(param, abbr string) (string, error) {
+	stn, err := lookupStation(param, abbr)
+	return stn.Abbr, err
+}
+
+const maxStationSuggestions = 3
+
+// suggestStations finds abbreviations that are within a couple of typos of the
+// input, or whose station name contains the input.
+func suggestStations(value string) []string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return nil
+	}
+
+	type candidate struct {
+		abbr string
+		dist int
+	}
+	candidates := make([]candidate, 0)
+	for _, stn := range stationRegistry {
+		dist := levenshtein(value, stn.Abbr)
+		if dist > 2 && len(value) > 2 && strings.Contains(strings.ToLower(stn.Name), value) {
+			dist = 2
+		}
+		if dist <= 2 {
+			candidates = append(candidates, candidate{stn.Abbr, dist})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].dist < candidates[j].dist
+	})
+
+	out := make([]string, 0, maxStationSuggestions)
+	for i := 0; i < len(candidates) && i < maxStationSuggestions; i++ {
+		out = append(out, candidates[i].abbr)
+	}
+	return out
+}
+
+// levenshtein calculates the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, minInt(curr[j-1]+1, prev[j-1]+cost))
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
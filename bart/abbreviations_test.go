@@ -0,0 +1,107 @@
+package bart
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLookupStation(t *testing.T) {
+	for _, abbr := range []string{"embr", "EMBR", "EmBr", " embr "} {
+		got, err := LookupStation(abbr)
+		if err != nil {
+			t.Errorf("unexpected error for %q, %v", abbr, err)
+			continue
+		}
+		if got.Abbr != "embr" || got.Name != "Embarcadero" {
+			t.Errorf("wrong station for %q, %+v", abbr, got)
+		}
+	}
+
+	tests := []struct {
+		value               string
+		expectedSuggestions []string
+	}{
+		{value: "embx", expectedSuggestions: []string{"embr"}},
+		{value: "macarthur", expectedSuggestions: []string{"mcar"}},
+		{value: "qqqqqqq", expectedSuggestions: []string{}},
+	}
+	for _, test := range tests {
+		_, err := LookupStation(test.value)
+		var stnErr *InvalidStationError
+		if !errors.As(err, &stnErr) {
+			t.Errorf("expected %T for %q, got %v", stnErr, test.value, err)
+			continue
+		}
+		if stnErr.Value != test.value {
+			t.Errorf("wrong Value; got %q, expected %q", stnErr.Value, test.value)
+		}
+		if len(stnErr.Suggestions) != len(test.expectedSuggestions) {
+			t.Errorf("wrong Suggestions for %q; got %v, expected %v", test.value, stnErr.Suggestions, test.expectedSuggestions)
+			continue
+		}
+		for i, suggestion := range test.expectedSuggestions {
+			if stnErr.Suggestions[i] != suggestion {
+				t.Errorf("wrong Suggestions[%d]; got %q, expected %q", i, stnErr.Suggestions[i], suggestion)
+			}
+		}
+	}
+}
+
+func TestStationValidation(t *testing.T) {
+	t.Run("invalid values are not requested", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request %s", r.URL)
+		}))
+		defer server.Close()
+
+		client := NewClient(nil)
+		client.conf.BaseURL = server.URL
+
+		var err error
+		_, err = client.RequestETD("nope", "", "")
+		if !errors.Is(err, ErrInvalidOrig) {
+			t.Errorf("RequestETD: expected error to match %v, got %v", ErrInvalidOrig, err)
+		}
+		_, err = client.RequestStationInfo("")
+		if !errors.Is(err, ErrInvalidOrig) {
+			t.Errorf("RequestStationInfo: expected error to match %v, got %v", ErrInvalidOrig, err)
+		}
+		_, err = client.RequestStationAccess("mcarr")
+		if !errors.Is(err, ErrInvalidOrig) {
+			t.Errorf("RequestStationAccess: expected error to match %v, got %v", ErrInvalidOrig, err)
+		}
+		_, err = client.RequestStationSchedules("xxxx", "")
+		if !errors.Is(err, ErrInvalidOrig) {
+			t.Errorf("RequestStationSchedules: expected error to match %v, got %v", ErrInvalidOrig, err)
+		}
+		_, err = client.RequestDepartures(TripParams{Orig: "embr", Dest: "embx"})
+		if !errors.Is(err, ErrInvalidDest) {
+			t.Errorf("RequestDepartures: expected error to match %v, got %v", ErrInvalidDest, err)
+		}
+		var stnErr *InvalidStationError
+		if !errors.As(err, &stnErr) || stnErr.Param != "dest" || stnErr.Value != "embx" {
+			t.Errorf("RequestDepartures: unexpected error %#v", err)
+		}
+	})
+
+	t.Run("valid values are normalized", func(t *testing.T) {
+		var origs []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origs = append(origs, r.URL.Query().Get("orig"))
+			w.Write([]byte(`{"root":{"message":""}}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(nil)
+		client.conf.BaseURL = server.URL
+
+		if _, err := client.RequestETD("MCar", "", ""); err != nil {
+			t.Fatal(err)
+		}
+		if len(origs) != 1 || origs[0] != "mcar" {
+			t.Errorf("wrong orig values sent, %q", origs)
+		}
+	})
+}
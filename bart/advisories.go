@@ -1,5 +1,13 @@
 package bart
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
 func initAdvisoriesRequest(cmd string) (out apiRequest) {
 	out.route = "/bsa.aspx"
 	out.cmd = cmd
@@ -7,6 +15,15 @@ func initAdvisoriesRequest(cmd string) (out apiRequest) {
 	return
 }
 
+func initStationAdvisoriesRequest(cmd, orig string) (out apiRequest, err error) {
+	if orig, err = normalizeStation("orig", orig); err != nil {
+		return
+	}
+	out = initAdvisoriesRequest(cmd)
+	out.options["orig"] = []string{orig}
+	return
+}
+
 // AdvisoriesAPI is a namespace for advisory information requests to routes at
 // /bsa.aspx. See official docs at https://api.bart.gov/docs/bsa/.
 type AdvisoriesAPI struct {
@@ -23,8 +40,32 @@ func (a *AdvisoriesAPI) clientConf() *Config {
 // RequestBSA requests current advisory information. See official docs at
 // https://api.bart.gov/docs/bsa/bsa.aspx.
 func (a *AdvisoriesAPI) RequestBSA() (res AdvisoriesBSAResponse, err error) {
+	return a.RequestBSAContext(context.Background())
+}
+
+// RequestBSAContext is like RequestBSA, but uses ctx for the request.
+func (a *AdvisoriesAPI) RequestBSAContext(ctx context.Context) (res AdvisoriesBSAResponse, err error) {
 	params := initAdvisoriesRequest("bsa")
-	err = params.requestAPI(a, &res)
+	err = params.requestAPI(ctx, a, &res)
+	return
+}
+
+// RequestStationBSA requests current advisory information for one station. The
+// orig param must be a 4-letter abbreviation for a station name. Advisories
+// about the whole system are included. See official docs at
+// https://api.bart.gov/docs/bsa/bsa.aspx.
+func (a *AdvisoriesAPI) RequestStationBSA(orig string) (res AdvisoriesBSAResponse, err error) {
+	return a.RequestStationBSAContext(context.Background(), orig)
+}
+
+// RequestStationBSAContext is like RequestStationBSA, but uses ctx for the
+// request.
+func (a *AdvisoriesAPI) RequestStationBSAContext(ctx context.Context, orig string) (res AdvisoriesBSAResponse, err error) {
+	params, err := initStationAdvisoriesRequest("bsa", orig)
+	if err != nil {
+		return
+	}
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -32,19 +73,115 @@ func (a *AdvisoriesAPI) RequestBSA() (res AdvisoriesBSAResponse, err error) {
 type AdvisoriesBSAResponse struct {
 	Root struct {
 		ResponseMetaData
-		Data []struct {
-			Description CDATASection
-			Type        string
-			Posted      string
+		Data []Advisory
+	}
+}
+
+// UnmarshalJSON reads the advisories from the "bsa" field of a response from
+// the BART API, or from the "Data" field, which is where they are when the
+// response is marshaled again.
+func (r *AdvisoriesBSAResponse) UnmarshalJSON(in []byte) error {
+	type advisoriesBSAResponseJSON AdvisoriesBSAResponse
+	var out advisoriesBSAResponseJSON
+	if err := json.Unmarshal(in, &out); err != nil {
+		return err
+	}
+	if out.Root.Data == nil {
+		var bsa struct {
+			Root struct {
+				Data []Advisory `json:"bsa"`
+			}
+		}
+		if err := json.Unmarshal(in, &bsa); err != nil {
+			return err
 		}
+		out.Root.Data = bsa.Root.Data
+	}
+	*r = AdvisoriesBSAResponse(out)
+	return nil
+}
+
+// Advisory is one service advisory. The Station field is "BART" for advisories
+// about the whole system. The Posted, Expires fields are formatted like
+// "Sat Oct 17 2026 08:02 AM PDT". Use the PostedTime, ExpiresTime methods to
+// parse them.
+type Advisory struct {
+	ID          string `json:"@id"`
+	Station     string
+	Type        AdvisoryType
+	Description CDATASection
+	SMSText     CDATASection `json:"sms_text"`
+	Posted      string
+	Expires     string
+}
+
+// AdvisoryType is the kind of an Advisory.
+type AdvisoryType string
+
+// These are the values of AdvisoryType. When there are no advisories, BART
+// responds with one Advisory of an empty AdvisoryType, which says so in its
+// Description.
+const (
+	AdvisoryDelay     AdvisoryType = "DELAY"
+	AdvisoryEmergency AdvisoryType = "EMERGENCY"
+	AdvisoryElevator  AdvisoryType = "ELEVATOR"
+)
+
+// AdvisoryTimeLayout is the format of the Posted, Expires fields of an
+// Advisory.
+const AdvisoryTimeLayout = "Mon Jan 2 2006 3:04 PM MST"
+
+// PostedTime parses the Posted field. It's the zero-value if the field is
+// empty.
+func (a Advisory) PostedTime() (time.Time, error) {
+	return parseAdvisoryTime(a.Posted)
+}
+
+// ExpiresTime parses the Expires field. It's the zero-value if the field is
+// empty.
+func (a Advisory) ExpiresTime() (time.Time, error) {
+	return parseAdvisoryTime(a.Expires)
+}
+
+func parseAdvisoryTime(in string) (out time.Time, err error) {
+	in = strings.TrimSpace(in)
+	if in == "" {
+		return
+	}
+	if out, err = time.ParseInLocation(AdvisoryTimeLayout, in, Location); err != nil {
+		err = fmt.Errorf("bart: invalid advisory time %q: %w", in, err)
 	}
+	return
 }
 
 // RequestElevator requests current elevator status information. See official
 // docs at https://api.bart.gov/docs/bsa/elev.aspx.
 func (a *AdvisoriesAPI) RequestElevator() (res AdvisoriesElevatorResponse, err error) {
+	return a.RequestElevatorContext(context.Background())
+}
+
+// RequestElevatorContext is like RequestElevator, but uses ctx for the request.
+func (a *AdvisoriesAPI) RequestElevatorContext(ctx context.Context) (res AdvisoriesElevatorResponse, err error) {
 	params := initAdvisoriesRequest("elev")
-	err = params.requestAPI(a, &res)
+	err = params.requestAPI(ctx, a, &res)
+	return
+}
+
+// RequestStationElevator requests current elevator status information for one
+// station. The orig param must be a 4-letter abbreviation for a station name.
+// See official docs at https://api.bart.gov/docs/bsa/elev.aspx.
+func (a *AdvisoriesAPI) RequestStationElevator(orig string) (res AdvisoriesElevatorResponse, err error) {
+	return a.RequestStationElevatorContext(context.Background(), orig)
+}
+
+// RequestStationElevatorContext is like RequestStationElevator, but uses ctx
+// for the request.
+func (a *AdvisoriesAPI) RequestStationElevatorContext(ctx context.Context, orig string) (res AdvisoriesElevatorResponse, err error) {
+	params, err := initStationAdvisoriesRequest("elev", orig)
+	if err != nil {
+		return
+	}
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -52,21 +189,57 @@ func (a *AdvisoriesAPI) RequestElevator() (res AdvisoriesElevatorResponse, err e
 type AdvisoriesElevatorResponse struct {
 	Root struct {
 		ResponseMetaData
-		Data []struct {
-			Station     string
-			Type        string
-			Description CDATASection
-			Posted      string
-			Expires     string
-		} `json:"bsa"`
+		Data []ElevatorStatus `json:"bsa"`
+	}
+}
+
+// ElevatorStatus is the status of the elevators at one station, or of every
+// station when the Station field is "BART". When all elevators are working,
+// the Type field is empty. The Posted, Expires fields are formatted like
+// AdvisoryTimeLayout.
+type ElevatorStatus struct {
+	Station     string
+	Type        AdvisoryType
+	Description CDATASection
+	Posted      string
+	Expires     string
+}
+
+// PostedTime parses the Posted field. It's the zero-value if the field is
+// empty.
+func (e ElevatorStatus) PostedTime() (time.Time, error) {
+	return parseAdvisoryTime(e.Posted)
+}
+
+// ExpiresTime parses the Expires field. It's the zero-value if the field is
+// empty.
+func (e ElevatorStatus) ExpiresTime() (time.Time, error) {
+	return parseAdvisoryTime(e.Expires)
+}
+
+// Advisory is the elevator status as an Advisory, so it can be handled along
+// with service advisories.
+func (e ElevatorStatus) Advisory() Advisory {
+	return Advisory{
+		Station:     e.Station,
+		Type:        e.Type,
+		Description: e.Description,
+		Posted:      e.Posted,
+		Expires:     e.Expires,
 	}
 }
 
 // RequestTrainCount requests the number of trains currently active in the
 // system. See official docs at: https://api.bart.gov/docs/bsa/count.aspx.
 func (a *AdvisoriesAPI) RequestTrainCount() (res AdvisoriesTrainCountResponse, err error) {
+	return a.RequestTrainCountContext(context.Background())
+}
+
+// RequestTrainCountContext is like RequestTrainCount, but uses ctx for the
+// request.
+func (a *AdvisoriesAPI) RequestTrainCountContext(ctx context.Context) (res AdvisoriesTrainCountResponse, err error) {
 	params := initAdvisoriesRequest("count")
-	err = params.requestAPI(a, &res)
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
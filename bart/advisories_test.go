@@ -0,0 +1,120 @@
+package bart
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAdvisoryTimes(t *testing.T) {
+	adv := Advisory{
+		Posted:  "Sat Oct 17 2026 08:02 AM PDT",
+		Expires: "Thu Dec 31 2026 11:59 PM PST",
+	}
+
+	posted, err := adv.PostedTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Date(2026, time.October, 17, 8, 2, 0, 0, Location); !posted.Equal(expected) {
+		t.Errorf("wrong PostedTime; got %v, expected %v", posted, expected)
+	}
+	expires, err := adv.ExpiresTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Date(2026, time.December, 31, 23, 59, 0, 0, Location); !expires.Equal(expected) {
+		t.Errorf("wrong ExpiresTime; got %v, expected %v", expires, expected)
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		got, err := Advisory{}.ExpiresTime()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.IsZero() {
+			t.Errorf("expected zero-value, got %v", got)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := (Advisory{Posted: "10/17/2026 08:02 AM"}).PostedTime(); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestStationAdvisories(t *testing.T) {
+	client := NewClient(nil)
+
+	if _, err := client.RequestStationBSA("nope"); !errors.Is(err, ErrInvalidOrig) {
+		t.Errorf("expected %v, got %v", ErrInvalidOrig, err)
+	}
+	if _, err := client.RequestStationElevator(""); !errors.Is(err, ErrInvalidOrig) {
+		t.Errorf("expected %v, got %v", ErrInvalidOrig, err)
+	}
+
+	req, err := initStationAdvisoriesRequest("elev", "MCar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.cmd != "elev" || req.options["orig"][0] != "mcar" {
+		t.Errorf("unexpected request, %+v", req)
+	}
+}
+
+func TestAdvisoriesJSON(t *testing.T) {
+	const fromAPI = `{"root":{"date":"10/17/2026","bsa":[
+		{"@id":"229331","station":"BART","type":"DELAY","description":{"#cdata-section":"10-minute delay."},"posted":"Sat Oct 17 2026 08:02 AM PDT"}
+	],"message":""}}`
+
+	t.Run("bsa", func(t *testing.T) {
+		var res AdvisoriesBSAResponse
+		if err := json.Unmarshal([]byte(fromAPI), &res); err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Root.Data) != 1 || res.Root.Data[0].ID != "229331" || res.Root.Date != "10/17/2026" {
+			t.Fatalf("unexpected response, %+v", res.Root)
+		}
+
+		out, err := json.Marshal(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fields struct{ Root map[string]json.RawMessage }
+		if err = json.Unmarshal(out, &fields); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := fields.Root["Data"]; !ok {
+			t.Errorf("expected advisories in the Data field, got %s", out)
+		}
+
+		var again AdvisoriesBSAResponse
+		if err = json.Unmarshal(out, &again); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(again, res) {
+			t.Errorf("wrong response after a round trip;\ngot      %+v\nexpected %+v", again, res)
+		}
+	})
+
+	t.Run("elev", func(t *testing.T) {
+		var res AdvisoriesElevatorResponse
+		if err := json.Unmarshal([]byte(fromAPI), &res); err != nil {
+			t.Fatal(err)
+		}
+		out, err := json.Marshal(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var again AdvisoriesElevatorResponse
+		if err = json.Unmarshal(out, &again); err != nil {
+			t.Fatal(err)
+		}
+		if len(again.Root.Data) != 1 || !reflect.DeepEqual(again, res) {
+			t.Errorf("wrong response after a round trip;\ngot      %+v\nexpected %+v", again, res)
+		}
+	})
+}
@@ -0,0 +1,299 @@
+package bart
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Fingerprint identifies an Advisory by its type, station, description and
+// posted time. The BART API doesn't give advisories stable IDs, so use this to
+// tell whether an advisory is the same one seen in an earlier response.
+func (a Advisory) Fingerprint() string {
+	sum := sha1.Sum([]byte(strings.Join([]string{
+		strings.ToUpper(strings.TrimSpace(string(a.Type))),
+		strings.ToUpper(strings.TrimSpace(a.Station)),
+		strings.TrimSpace(a.Description.Value),
+		strings.TrimSpace(a.Posted),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// isPlaceholder says whether a is the item BART sends when there are no
+// advisories, such as "No delays reported."
+func (a Advisory) isPlaceholder() bool {
+	return a.Type == "" && strings.TrimSpace(a.Posted) == ""
+}
+
+// AdvisoryEventType is the kind of change in an AdvisoryEvent.
+type AdvisoryEventType int
+
+const (
+	// AdvisoryAdded is for an advisory which wasn't in the previous response.
+	AdvisoryAdded AdvisoryEventType = iota + 1
+	// AdvisoryCleared is for an advisory which is no longer in the response.
+	AdvisoryCleared
+	// AdvisoryWatchError is for a failed request, or a failure to read or
+	// write the state file. The AdvisoryWatcher keeps going.
+	AdvisoryWatchError
+)
+
+func (t AdvisoryEventType) String() string {
+	switch t {
+	case AdvisoryAdded:
+		return "added"
+	case AdvisoryCleared:
+		return "cleared"
+	case AdvisoryWatchError:
+		return "error"
+	default:
+		return fmt.Sprintf("AdvisoryEventType(%d)", int(t))
+	}
+}
+
+// AdvisoryEvent is a change in the advisories, found by an AdvisoryWatcher.
+type AdvisoryEvent struct {
+	Type AdvisoryEventType
+	// Cmd is "bsa" for service advisories, or "elev" for elevator outages.
+	Cmd         string
+	Advisory    Advisory
+	Fingerprint string
+	// Err is the error for AdvisoryWatchError.
+	Err error
+	// Time is when the advisories were received.
+	Time time.Time
+}
+
+// An AdvisoryWatcher polls for advisories and elevator outages, and reports the
+// ones that were added or cleared since the previous request. Make one with
+// NewAdvisoryWatcher, set any optional fields, then call Watch.
+//
+// Advisories are told apart by their Fingerprint. Set StateFile to remember
+// them between restarts, so the same advisory isn't reported twice.
+type AdvisoryWatcher struct {
+	// Interval is the time between requests. The default is 1 minute.
+	Interval time.Duration
+	// Jitter is the fraction, from 0 to 1, of each interval that is
+	// randomized.
+	Jitter float64
+	// MaxBackoff caps the time between requests while they are failing. Each
+	// failure doubles the interval. The default is 5 minutes.
+	MaxBackoff time.Duration
+	// Elevators also watches elevator outages.
+	Elevators bool
+	// StateFile is the path to a JSON file of the advisories seen so far. It's
+	// read when Watch starts, and written after the events of each change are
+	// sent. If Watch stops before then, those events are sent again after a
+	// restart. If it's empty, then the state is only kept in memory, and every
+	// advisory in the first response is reported as added.
+	StateFile string
+
+	api *AdvisoriesAPI
+	now func() time.Time
+}
+
+// NewAdvisoryWatcher makes an AdvisoryWatcher which requests advisories from
+// api.
+func NewAdvisoryWatcher(api *AdvisoriesAPI) *AdvisoryWatcher {
+	return &AdvisoryWatcher{
+		Interval:   time.Minute,
+		Jitter:     0.1,
+		MaxBackoff: 5 * time.Minute,
+		api:        api,
+		now:        time.Now,
+	}
+}
+
+// advisoryState is the advisories seen so far, by cmd, then by fingerprint.
+// It's also the format of the state file.
+type advisoryState map[string]map[string]Advisory
+
+// Watch starts polling in a goroutine and sends events to the output channel,
+// until ctx is done. Then the channel is closed.
+func (w *AdvisoryWatcher) Watch(ctx context.Context) <-chan AdvisoryEvent {
+	out := make(chan AdvisoryEvent)
+	go w.run(ctx, out)
+	return out
+}
+
+func (w *AdvisoryWatcher) run(ctx context.Context, out chan<- AdvisoryEvent) {
+	defer close(out)
+
+	send := func(events []AdvisoryEvent) bool {
+		for _, event := range events {
+			select {
+			case <-ctx.Done():
+				return false
+			case out <- event:
+			}
+		}
+		return true
+	}
+
+	state, err := w.loadState()
+	if err != nil {
+		state = make(advisoryState)
+		if !send([]AdvisoryEvent{{Type: AdvisoryWatchError, Err: err, Time: w.now()}}) {
+			return
+		}
+	}
+
+	cmds := []string{"bsa"}
+	if w.Elevators {
+		cmds = append(cmds, "elev")
+	}
+
+	var failures int
+	for {
+		var events []AdvisoryEvent
+		var failed, changed bool
+		for _, cmd := range cmds {
+			list, err := w.request(ctx, cmd)
+			now := w.now()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				failed = true
+				events = append(events, AdvisoryEvent{Type: AdvisoryWatchError, Cmd: cmd, Err: err, Time: now})
+				continue
+			}
+			current := indexAdvisories(list)
+			diff := diffAdvisories(cmd, state[cmd], current)
+			for i := range diff {
+				diff[i].Time = now
+			}
+			changed = changed || len(diff) > 0 || state[cmd] == nil
+			events = append(events, diff...)
+			state[cmd] = current
+		}
+
+		if !send(events) {
+			return
+		}
+		// Only save the changes once they're sent, so they're reported again
+		// after a restart if they weren't.
+		if changed {
+			if err := w.saveState(state); err != nil {
+				if !send([]AdvisoryEvent{{Type: AdvisoryWatchError, Err: err, Time: w.now()}}) {
+					return
+				}
+			}
+		}
+
+		if failed {
+			failures++
+		} else {
+			failures = 0
+		}
+		if sleep(ctx, pollDelay(w.interval(), w.MaxBackoff, w.Jitter, failures)) != nil {
+			return
+		}
+	}
+}
+
+func (w *AdvisoryWatcher) interval() time.Duration {
+	if w.Interval <= 0 {
+		return time.Minute
+	}
+	return w.Interval
+}
+
+func (w *AdvisoryWatcher) request(ctx context.Context, cmd string) ([]Advisory, error) {
+	if cmd == "elev" {
+		res, err := w.api.RequestElevatorContext(ctx)
+		out := make([]Advisory, len(res.Root.Data))
+		for i, elev := range res.Root.Data {
+			out[i] = elev.Advisory()
+		}
+		return out, err
+	}
+	res, err := w.api.RequestBSAContext(ctx)
+	return res.Root.Data, err
+}
+
+func indexAdvisories(list []Advisory) map[string]Advisory {
+	out := make(map[string]Advisory, len(list))
+	for _, adv := range list {
+		if !adv.isPlaceholder() {
+			out[adv.Fingerprint()] = adv
+		}
+	}
+	return out
+}
+
+// diffAdvisories lists the cleared advisories, then the added ones. Each group
+// is sorted by fingerprint, so the order is stable.
+func diffAdvisories(cmd string, prev, cur map[string]Advisory) []AdvisoryEvent {
+	var cleared, added []string
+	for fp := range prev {
+		if _, ok := cur[fp]; !ok {
+			cleared = append(cleared, fp)
+		}
+	}
+	for fp := range cur {
+		if _, ok := prev[fp]; !ok {
+			added = append(added, fp)
+		}
+	}
+	sort.Strings(cleared)
+	sort.Strings(added)
+
+	out := make([]AdvisoryEvent, 0, len(cleared)+len(added))
+	for _, fp := range cleared {
+		out = append(out, AdvisoryEvent{Type: AdvisoryCleared, Cmd: cmd, Advisory: prev[fp], Fingerprint: fp})
+	}
+	for _, fp := range added {
+		out = append(out, AdvisoryEvent{Type: AdvisoryAdded, Cmd: cmd, Advisory: cur[fp], Fingerprint: fp})
+	}
+	return out
+}
+
+func (w *AdvisoryWatcher) loadState() (advisoryState, error) {
+	out := make(advisoryState)
+	if w.StateFile == "" {
+		return out, nil
+	}
+	data, err := os.ReadFile(w.StateFile)
+	if os.IsNotExist(err) {
+		return out, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("bart: invalid state file %s: %w", w.StateFile, err)
+	}
+	return out, nil
+}
+
+// saveState writes the state to a temporary file, then renames it, so a crash
+// doesn't leave a partial file behind.
+func (w *AdvisoryWatcher) saveState(state advisoryState) error {
+	if w.StateFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(w.StateFile), filepath.Base(w.StateFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), w.StateFile)
+}
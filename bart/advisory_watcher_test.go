@@ -0,0 +1,155 @@
+package bart
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAdvisoryFingerprint(t *testing.T) {
+	adv := Advisory{
+		ID:          "229331",
+		Station:     "BART",
+		Type:        AdvisoryDelay,
+		Description: CDATASection{Value: "There is a 10-minute delay at West Oakland."},
+		Posted:      "Sat Oct 17 2026 08:02 AM PDT",
+	}
+
+	same := adv
+	same.ID = "229332"
+	same.Expires = "Sat Oct 17 2026 11:59 PM PDT"
+	if adv.Fingerprint() != same.Fingerprint() {
+		t.Error("expected same Fingerprint when only ID, Expires differ")
+	}
+
+	other := adv
+	other.Description.Value = "There is a 20-minute delay at West Oakland."
+	if adv.Fingerprint() == other.Fingerprint() {
+		t.Error("expected different Fingerprint when Description differs")
+	}
+}
+
+func TestAdvisoryWatcher(t *testing.T) {
+	const (
+		delay    = `{"station":"BART","type":"DELAY","description":{"#cdata-section":"10-minute delay."},"posted":"Sat Oct 17 2026 08:02 AM PDT"}`
+		delay2   = `{"station":"BART","type":"DELAY","description":{"#cdata-section":"20-minute delay."},"posted":"Sat Oct 17 2026 08:30 AM PDT"}`
+		none     = `{"station":"","description":{"#cdata-section":"No delays reported."}}`
+		elevator = `{"station":"MCAR","type":"ELEVATOR","description":{"#cdata-section":"MacArthur elevator out."},"posted":"Fri Oct 16 2026 06:41 PM PDT"}`
+	)
+	var (
+		mu  sync.Mutex
+		bsa = none
+	)
+	setBSA := func(val string) {
+		mu.Lock()
+		defer mu.Unlock()
+		bsa = val
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		item := bsa
+		if r.URL.Query().Get("cmd") == "elev" {
+			item = elevator
+		}
+		fmt.Fprintf(w, `{"root":{"bsa":[%s],"message":""}}`, item)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.conf.BaseURL = server.URL
+	stateFile := filepath.Join(t.TempDir(), "advisories.json")
+
+	newWatcher := func() *AdvisoryWatcher {
+		watcher := NewAdvisoryWatcher(client.AdvisoriesAPI)
+		watcher.Interval = time.Millisecond
+		watcher.Jitter = 0
+		watcher.Elevators = true
+		watcher.StateFile = stateFile
+		return watcher
+	}
+
+	watch := func(t *testing.T, expected []AdvisoryEventType, afterFirst func()) {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events := newWatcher().Watch(ctx)
+
+		for i, typ := range expected {
+			var event AdvisoryEvent
+			select {
+			case event = <-events:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("event %d; timed out waiting for %v", i, typ)
+			}
+			if event.Type != typ {
+				t.Errorf("event %d; wrong Type; got %v, expected %v (%+v)", i, event.Type, typ, event)
+			}
+			if i == 0 && afterFirst != nil {
+				afterFirst()
+			}
+		}
+		cancel()
+		for range events {
+			// Drain the channel until it's closed.
+		}
+	}
+
+	t.Run("first run", func(t *testing.T) {
+		// The elevator outage is new, but "No delays reported." isn't an
+		// advisory. Then a delay is posted.
+		watch(t, []AdvisoryEventType{AdvisoryAdded, AdvisoryAdded}, func() { setBSA(delay) })
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		// The delay and elevator outage were already reported before the
+		// restart, so only the changes are reported.
+		setBSA(delay2)
+		watch(t, []AdvisoryEventType{AdvisoryCleared, AdvisoryAdded}, nil)
+	})
+
+	t.Run("cancel during send", func(t *testing.T) {
+		// Stop the watcher after it's sent the first of two events. Nothing is
+		// received after that, so the second event can't be sent.
+		setBSA(delay)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events := make(chan AdvisoryEvent)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			newWatcher().run(ctx, events)
+		}()
+		if event := <-events; event.Type != AdvisoryCleared {
+			t.Errorf("wrong Type; got %v, expected %v (%+v)", event.Type, AdvisoryCleared, event)
+		}
+		cancel()
+		<-done
+
+		// Neither change was saved, so both are reported after a restart.
+		watch(t, []AdvisoryEventType{AdvisoryCleared, AdvisoryAdded}, nil)
+	})
+}
+
+func TestDiffAdvisories(t *testing.T) {
+	a := Advisory{Type: AdvisoryDelay, Posted: "Sat Oct 17 2026 08:02 AM PDT"}
+	b := Advisory{Type: AdvisoryEmergency, Posted: "Sat Oct 17 2026 08:10 AM PDT"}
+	prev := indexAdvisories([]Advisory{a})
+	cur := indexAdvisories([]Advisory{b, {Description: CDATASection{Value: "No delays reported."}}})
+
+	got := diffAdvisories("bsa", prev, cur)
+	if len(got) != 2 {
+		t.Fatalf("wrong number of events; got %d, expected %d", len(got), 2)
+	}
+	if got[0].Type != AdvisoryCleared || got[0].Fingerprint != a.Fingerprint() {
+		t.Errorf("expected %v to be cleared, got %+v", a, got[0])
+	}
+	if got[1].Type != AdvisoryAdded || got[1].Fingerprint != b.Fingerprint() {
+		t.Errorf("expected %v to be added, got %+v", b, got[1])
+	}
+}
@@ -1,6 +1,7 @@
 package bart
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
@@ -8,27 +9,59 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	// Key is default API Key that BART gives to all developers. If you have
 	// registered your own key, then you should use the NewClient function.
-	Key     = "MW9S-E7SL-26DU-VV8V"
-	baseURL = "https://api.bart.gov/api"
+	Key = "MW9S-E7SL-26DU-VV8V"
+	// DefaultBaseURL is the root of the official BART API.
+	DefaultBaseURL = "https://api.bart.gov/api"
 )
 
 var defaultClientConf = &Config{
 	Key:     Key,
 	HTTP:    &http.Client{},
-	baseURL: baseURL,
+	BaseURL: DefaultBaseURL,
 }
 
-// A Config is a collection of named parameters for a Client.
+// A Config is a collection of named parameters for a Client. BaseURL is the root
+// of the API, such as a self-hosted mirror or a local stub. It must be an
+// absolute http or https URL. Route paths, such as "/etd.aspx", are joined to
+// it whether or not it has a trailing slash.
+//
+// Middleware wraps the Transport of the HTTP client, so headers, tracing or
+// logging can be added to every request. See the Middleware type for details.
+//
+// Retry is optional; when it's nil, failed requests are not retried.
+//
+// RateLimit is also optional. When it's set, every request made with this
+// Config waits on it, no matter which API namespace makes the request. To give
+// a route its own budget, put a RateLimiter in RouteRateLimits, keyed by the
+// path of the route, such as "/etd.aspx". A route in RouteRateLimits does not
+// wait on RateLimit. A nil value in RouteRateLimits means the route is not
+// limited.
+//
+// Cache is optional, too. When it's set, successful responses are stored there
+// for as long as CacheTTL says, which defaults to DefaultCacheTTL. Responses
+// served from the Cache have their ResponseMetaData.Cached field set to true.
 type Config struct {
-	Key     string
-	HTTP    *http.Client
-	baseURL string
+	Key             string
+	HTTP            *http.Client
+	BaseURL         string
+	Middleware      []Middleware
+	Retry           *RetryPolicy
+	RateLimit       *RateLimiter
+	RouteRateLimits map[string]*RateLimiter
+	Cache           Cache
+	CacheTTL        func(route, cmd string) time.Duration
+
+	// httpClient is the HTTP client with the Middleware applied.
+	httpClient *builtHTTPClient
 }
 
 // Client gives you easy access to several BART API endpoints. See examples for
@@ -47,7 +80,10 @@ type Client struct {
 // default settings. If you have registered our own API key, then specify
 // conf.Key. If conf.Key is empty, then the default API key is used. If
 // conf.HTTP is empty then the http client is an empty *http.Client from the
-// standard library.
+// standard library. If conf.BaseURL is empty, then DefaultBaseURL is used. The
+// conf.Middleware is applied to the HTTP client on the first request, and again
+// whenever conf.HTTP is replaced, so a new conf.HTTP takes effect on the next
+// request.
 func NewClient(conf *Config) *Client {
 	if conf == nil {
 		conf = &Config{}
@@ -58,7 +94,10 @@ func NewClient(conf *Config) *Client {
 	if conf.HTTP == nil {
 		conf.HTTP = &http.Client{}
 	}
-	conf.baseURL = baseURL
+	if conf.BaseURL == "" {
+		conf.BaseURL = DefaultBaseURL
+	}
+	conf.httpClient = &builtHTTPClient{}
 	return &Client{
 		conf:          conf,
 		AdvisoriesAPI: &AdvisoriesAPI{conf},
@@ -69,6 +108,33 @@ func NewClient(conf *Config) *Client {
 	}
 }
 
+func (c *Client) clientConf() *Config {
+	if c != nil && c.conf != nil {
+		return c.conf
+	}
+	return defaultClientConf
+}
+
+// RequestRaw requests the cmd at route, such as "/etd.aspx", and returns the
+// response body without unmarshaling it. The options are added to the query,
+// except for the cmd, key and json parameters, which are set by the Client.
+// It's meant for passing responses along as-is, such as in a proxy, so the
+// inputs are not validated. The request is otherwise the same as any other: it
+// uses the Config's rate limits, retry policy and cache, and an error from the
+// BART API is an *APIError with the response in its Body field.
+func (c *Client) RequestRaw(ctx context.Context, route, cmd string, options url.Values) (res json.RawMessage, err error) {
+	params := apiRequest{route: route, cmd: cmd, options: make(map[string][]string)}
+	for key, vals := range options {
+		switch strings.ToLower(key) {
+		case "cmd", "key", "json":
+			continue
+		}
+		params.options[key] = vals
+	}
+	err = params.requestAPI(ctx, c, &res)
+	return
+}
+
 // ResponseMetaData is contains some data about the response. Not all of the
 // fields are filled by every API endpoint.
 type ResponseMetaData struct {
@@ -76,6 +142,9 @@ type ResponseMetaData struct {
 	Date    string      `json:",omitempty"`
 	Time    string      `json:",omitempty"`
 	Message interface{} `json:",omitempty"`
+	// Cached is true when the response came from the Config.Cache instead of
+	// the BART API.
+	Cached bool `json:"-"`
 }
 
 // CDATASection is merely a helper for unmarshaling certain fields. The original
@@ -87,6 +156,27 @@ type CDATASection struct {
 	Value string `json:"#cdata-section"`
 }
 
+// endpoint joins the route to the BaseURL.
+func (c *Config) endpoint(route string) (*url.URL, error) {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	out, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BaseURL %q: %w", base, err)
+	}
+	if (out.Scheme != "http" && out.Scheme != "https") || out.Host == "" {
+		return nil, fmt.Errorf("invalid BaseURL %q: must be an absolute http or https URL", base)
+	}
+	if out.RawQuery != "" || out.Fragment != "" {
+		return nil, fmt.Errorf("invalid BaseURL %q: must not have a query or fragment", base)
+	}
+	out.Path = strings.TrimRight(out.Path, "/") + "/" + strings.TrimLeft(route, "/")
+	out.RawPath = ""
+	return out, nil
+}
+
 type configuredClient interface {
 	clientConf() *Config
 }
@@ -97,7 +187,23 @@ type apiRequest struct {
 	options map[string][]string
 }
 
-func (p apiRequest) requestAPI(cc configuredClient, out interface{}) error {
+// setSched adds the schedule number to the request, unless it's the
+// zero-value, which means the current schedule.
+func (p apiRequest) setSched(sched int) error {
+	if sched < 0 {
+		return &InvalidSchedError{Sched: sched}
+	} else if sched > 0 {
+		p.options["sched"] = []string{strconv.Itoa(sched)}
+	}
+	return nil
+}
+
+// requestAPI performs the request and unmarshals the response body into out.
+// The request is bound to ctx, so cancelling it aborts the request at any
+// point, including while the response body is being read. Every attempt waits
+// on the configured RateLimiter, and failed attempts are retried according to
+// the configured RetryPolicy.
+func (p apiRequest) requestAPI(ctx context.Context, cc configuredClient, out interface{}) error {
 	conf := cc.clientConf()
 
 	values := make(url.Values)
@@ -110,16 +216,69 @@ func (p apiRequest) requestAPI(cc configuredClient, out interface{}) error {
 		}
 	}
 
-	uri := conf.baseURL + p.route + "?" + values.Encode()
-	res, err := conf.HTTP.Get(uri)
+	endpoint, err := conf.endpoint(p.route)
 	if err != nil {
 		return err
 	}
+	endpoint.RawQuery = values.Encode()
+	uri := endpoint.String()
+	ttl := conf.cacheTTL(p.route, p.cmd)
+	var key string
+	if ttl > 0 {
+		key = cacheKey(*endpoint, values)
+		if raw, ok := conf.Cache.Get(key); ok {
+			if err := json.Unmarshal(raw, out); err != nil {
+				return err
+			}
+			markCached(out)
+			return nil
+		}
+	}
+
+	raw, err := conf.Retry.do(ctx, conf.rateLimiter(p.route), func() ([]byte, http.Header, error) {
+		return p.attempt(ctx, conf, uri)
+	})
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(raw, out); err != nil {
+		return err
+	}
+	if ttl > 0 {
+		conf.Cache.Set(key, raw, ttl)
+	}
+	return nil
+}
+
+// cacheKey is the URL of a request without the API key, so clients with
+// different keys share responses, and the key isn't stored in the Cache.
+func cacheKey(endpoint url.URL, values url.Values) string {
+	query := make(url.Values, len(values))
+	for key, vals := range values {
+		if key != "key" {
+			query[key] = vals
+		}
+	}
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String()
+}
+
+// attempt makes one request to uri. It returns the response body if there is no
+// error. Otherwise, the response headers are returned if there are any.
+func (p apiRequest) attempt(ctx context.Context, conf *Config, uri string) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := conf.client().Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
 	defer res.Body.Close()
 
 	raw, err := io.ReadAll(res.Body)
 	if err != nil {
-		return err
+		return nil, res.Header, err
 	}
 
 	// It seems like the BART API just started returning non-200 status codes
@@ -127,18 +286,40 @@ func (p apiRequest) requestAPI(cc configuredClient, out interface{}) error {
 	// there is some documentation, assume there might be an error buried in the
 	// response body.
 	if err := checkAPIError(raw); err != nil {
-		return err
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			apiErr.StatusCode = res.StatusCode
+			apiErr.Route = p.route
+			apiErr.Cmd = p.cmd
+			apiErr.Body = raw
+			return nil, res.Header, apiErr
+		}
+		if res.StatusCode < http.StatusBadRequest {
+			return nil, res.Header, err
+		}
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		// There is no error message in the body, but the status code says
+		// something went wrong.
+		return nil, res.Header, &APIError{
+			Text:       http.StatusText(res.StatusCode),
+			StatusCode: res.StatusCode,
+			Route:      p.route,
+			Cmd:        p.cmd,
+			Body:       raw,
+		}
 	}
 
-	return json.Unmarshal(raw, out)
+	return raw, res.Header, nil
 }
 
+// checkAPIError looks for an error message in the response body. An error
+// returned by the BART API is an *APIError, though only the Text and Details
+// fields are filled in here. Any other error means the body could not be read.
 func checkAPIError(in []byte) error {
 	var body struct {
 		Root struct {
-			Message struct {
-				Error interface{}
-			}
+			Message json.RawMessage
 		}
 	}
 
@@ -146,13 +327,6 @@ func checkAPIError(in []byte) error {
 	case nil:
 		// Attempt to crack open the error message below.
 		break
-	case *json.UnmarshalTypeError:
-		// Looks like most successful requests will have a Root.Message field
-		// that's actually a string.
-		if err.Field == "Root.Message" && err.Value == "string" {
-			return nil
-		}
-		return err
 	case *json.SyntaxError:
 		// Handle any errors from the BART API that are formatted as XML. As of
 		// 2018, the JSON API format is in beta and they never got around to
@@ -164,36 +338,61 @@ func checkAPIError(in []byte) error {
 			Text    string `xml:"message>error>text"`
 			Details string `xml:"message>error>details"`
 		}
-		xmlParseErr := xml.Unmarshal(in, &xmlMessage)
-		if xmlParseErr != nil {
+		if xmlParseErr := xml.Unmarshal(in, &xmlMessage); xmlParseErr != nil {
 			return xmlParseErr
 		}
-		return fmt.Errorf("error: %s. %s", xmlMessage.Text, xmlMessage.Details)
+		if xmlMessage.Text == "" && xmlMessage.Details == "" {
+			return err
+		}
+		return &APIError{Text: xmlMessage.Text, Details: xmlMessage.Details}
 	default:
-		return fmt.Errorf("error checking api error: %v", err)
+		return fmt.Errorf("error checking api error: %w", err)
 	}
 
-	switch val := body.Root.Message.Error.(type) {
+	// Looks like most successful requests will have a Root.Message field
+	// that's actually a string.
+	var message struct {
+		Error interface{}
+	}
+	if len(body.Root.Message) == 0 || body.Root.Message[0] != '{' {
+		return nil
+	}
+	if err := json.Unmarshal(body.Root.Message, &message); err != nil {
+		return fmt.Errorf("error checking api error: %w", err)
+	}
+
+	switch val := message.Error.(type) {
 	case nil:
 		// Interpret this as a successful response.
-		break
+		return nil
 	case map[string]interface{}:
 		// Most of the time, the error value is a struct with the fields: Text
 		// and Details. However, accept other key-value pairs as well because
 		// changes to the error shape seem to happen without announcement.
 		// Hopefully, a decent error message can be built up this way.
-		msgs := make([]string, 0)
+		var out APIError
+		extras := make([]string, 0)
 		for k, v := range val {
-			msgs = append(msgs, fmt.Sprintf("%s: %v", k, v))
+			switch strings.ToLower(k) {
+			case "text":
+				out.Text = fmt.Sprint(v)
+			case "details":
+				out.Details = fmt.Sprint(v)
+			default:
+				extras = append(extras, fmt.Sprintf("%s: %v", k, v))
+			}
 		}
-		return errors.New(strings.Join(msgs, ", "))
+		sort.Strings(extras)
+		if out.Details != "" {
+			extras = append([]string{out.Details}, extras...)
+		}
+		out.Details = strings.Join(extras, ", ")
+		return &out
 	case string:
 		// Yep, sometimes it's a string.
-		return errors.New(val)
+		return &APIError{Text: val}
 	default:
 		// Not expected, but just go with the flow.
-		return errors.New(fmt.Sprintf("%s", val))
+		return &APIError{Text: fmt.Sprintf("%v", val)}
 	}
-
-	return nil
 }
@@ -1,8 +1,16 @@
 package bart
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
 	"testing"
+	"time"
 )
 
 type stubAPI struct{ conf *Config }
@@ -11,17 +19,17 @@ func (a *stubAPI) clientConf() *Config { return a.conf }
 
 func TestRequestAPI(t *testing.T) {
 	t.Run("errors", func(t *testing.T) {
-		runTest := func(t *testing.T, s stubHandler) {
+		runTest := func(t *testing.T, s stubHandler, expectedText string) *APIError {
 			server := makeTestServer(t, s)
 			defer server.Close()
 
 			client := stubAPI{conf: &Config{HTTP: &http.Client{}}}
-			client.conf.baseURL = server.URL
+			client.conf.BaseURL = server.URL
 
 			var out interface{}
 			params := apiRequest{route: s.expectedPath, cmd: "foo"}
 
-			got := params.requestAPI(&client, &out)
+			got := params.requestAPI(context.Background(), &client, &out)
 			if got == nil {
 				t.Fatal("expected error, got nil")
 			}
@@ -30,22 +38,58 @@ func TestRequestAPI(t *testing.T) {
 			// exact comformance, test that the client can handle all the
 			// various shapes of response body. Manually inspect messages here.
 			t.Logf(got.Error())
+
+			var apiErr *APIError
+			if !errors.As(got, &apiErr) {
+				t.Fatalf("expected %T, got %T", apiErr, got)
+			}
+			if apiErr.Text != expectedText {
+				t.Errorf("wrong Text; got %q, expected %q", apiErr.Text, expectedText)
+			}
+			if apiErr.StatusCode != http.StatusOK {
+				t.Errorf("wrong StatusCode; got %d, expected %d", apiErr.StatusCode, http.StatusOK)
+			}
+			if apiErr.Route != s.expectedPath {
+				t.Errorf("wrong Route; got %q, expected %q", apiErr.Route, s.expectedPath)
+			}
+			if apiErr.Cmd != "foo" {
+				t.Errorf("wrong Cmd; got %q, expected %q", apiErr.Cmd, "foo")
+			}
+			if len(apiErr.Body) < 1 {
+				t.Error("expected non-empty Body")
+			}
+			return apiErr
 		}
 
 		t.Run("xml", func(t *testing.T) {
-			runTest(t, stubHandler{
+			got := runTest(t, stubHandler{
 				expectedPath:     "/err_xml",
 				expectedCmd:      "foo",
 				responseFilename: "testdata/err.xml",
-			})
+			}, "Invalid cmd")
+			if !errors.Is(got, ErrInvalidCmd) {
+				t.Errorf("expected error to match %v", ErrInvalidCmd)
+			}
+			if got.Details == "" {
+				t.Error("expected non-empty Details")
+			}
 		})
 
 		t.Run("json object", func(t *testing.T) {
-			runTest(t, stubHandler{
+			got := runTest(t, stubHandler{
 				expectedPath:     "/err_json_object",
 				expectedCmd:      "foo",
 				responseFilename: "testdata/err_object.json",
-			})
+			}, "Invalid cmd")
+			if !errors.Is(got, ErrInvalidCmd) {
+				t.Errorf("expected error to match %v", ErrInvalidCmd)
+			}
+			if errors.Is(got, ErrInvalidKey) {
+				t.Errorf("did not expect error to match %v", ErrInvalidKey)
+			}
+			if got.Details == "" {
+				t.Error("expected non-empty Details")
+			}
 		})
 
 		t.Run("json string", func(t *testing.T) {
@@ -53,7 +97,30 @@ func TestRequestAPI(t *testing.T) {
 				expectedPath:     "/err_json_string",
 				expectedCmd:      "foo",
 				responseFilename: "testdata/err_string.json",
-			})
+			}, "date/time not in timetable or allowed period")
+		})
+
+		t.Run("status code", func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+				fmt.Fprint(w, "<html><body>Bad Gateway</body></html>")
+			}))
+			defer server.Close()
+
+			client := stubAPI{conf: &Config{HTTP: &http.Client{}}}
+			client.conf.BaseURL = server.URL
+
+			var out interface{}
+			params := apiRequest{route: "/etd.aspx", cmd: "etd"}
+			got := params.requestAPI(context.Background(), &client, &out)
+
+			var apiErr *APIError
+			if !errors.As(got, &apiErr) {
+				t.Fatalf("expected %T, got %T (%v)", apiErr, got, got)
+			}
+			if apiErr.StatusCode != http.StatusBadGateway {
+				t.Errorf("wrong StatusCode; got %d, expected %d", apiErr.StatusCode, http.StatusBadGateway)
+			}
 		})
 	})
 
@@ -66,15 +133,94 @@ func TestRequestAPI(t *testing.T) {
 		defer server.Close()
 
 		client := stubAPI{conf: &Config{HTTP: &http.Client{}}}
-		client.conf.baseURL = server.URL
+		client.conf.BaseURL = server.URL
 
 		// The Root.Message field doesn't always contain an error. Sometimes,
 		// successful requests will be an empty string here.
 		params := apiRequest{route: "/ok", cmd: "foo"}
 		var out interface{}
-		got := params.requestAPI(&client, &out)
+		got := params.requestAPI(context.Background(), &client, &out)
 		if got != nil {
 			t.Fatalf("unexpected error, %v", got)
 		}
 	})
+
+	t.Run("context", func(t *testing.T) {
+		// The handler sends part of the body, then stalls until the request is
+		// abandoned. Cancelling the context should interrupt the body read.
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"root":`)
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		client := stubAPI{conf: &Config{HTTP: &http.Client{}}}
+		client.conf.BaseURL = server.URL
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		params := apiRequest{route: "/slow", cmd: "foo"}
+		var out interface{}
+		got := params.requestAPI(ctx, &client, &out)
+		if !errors.Is(got, context.DeadlineExceeded) {
+			t.Fatalf("expected %v, got %v", context.DeadlineExceeded, got)
+		}
+	})
+}
+
+func TestRequestRaw(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if r.URL.Query().Get("orig") == "nope" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"root":{"message":{"error":{"text":"Invalid orig"}}}}`)
+			return
+		}
+		http.ServeFile(w, r, "testdata/ok.json")
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Key: "my-key", BaseURL: server.URL})
+
+	t.Run("ok", func(t *testing.T) {
+		options := url.Values{"orig": {"mcar"}, "key": {"someone-else"}, "cmd": {"nope"}}
+		got, err := client.RequestRaw(context.Background(), "/etd.aspx", "etd", options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := os.ReadFile("testdata/ok.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(bytes.TrimSpace(got), bytes.TrimSpace(expected)) {
+			t.Errorf("wrong body; got %s, expected %s", got, expected)
+		}
+		for key, expected := range map[string]string{"cmd": "etd", "key": "my-key", "json": "y", "orig": "mcar"} {
+			if got := query.Get(key); got != expected {
+				t.Errorf("wrong %s; got %q, expected %q", key, got, expected)
+			}
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := client.RequestRaw(context.Background(), "/etd.aspx", "etd", url.Values{"orig": {"nope"}})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected %T, got %T (%v)", apiErr, err, err)
+		}
+		if !errors.Is(err, ErrInvalidOrig) {
+			t.Errorf("expected %v, got %v", ErrInvalidOrig, err)
+		}
+		if apiErr.StatusCode != http.StatusBadRequest || len(apiErr.Body) == 0 {
+			t.Errorf("expected the status code and body, got %d, %q", apiErr.StatusCode, apiErr.Body)
+		}
+	})
 }
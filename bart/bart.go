@@ -4,9 +4,8 @@
 //
 // The API request example functions are written with vague regard to the
 // output. However, to execute the examples with go test, we must specify some
-// kind of output. Making real requests to the BART API in tests (as opposed to
-// stubbing responses) is meant to expose unexpected errors or panics, which
-// should help make response handling better in the long run.
+// kind of output. The examples send requests to the fake BART API in the
+// barttest package, so they run offline.
 package bart
 
 import "strconv"
@@ -16,7 +15,7 @@ import "strconv"
 type Bool bool
 
 func (b *Bool) UnmarshalJSON(data []byte) error {
-	val, err := strconv.ParseBool(string(data))
+	val, err := strconv.ParseBool(unquoteJSON(data))
 	if err != nil {
 		return err
 	}
@@ -30,7 +29,7 @@ func (b *Bool) UnmarshalJSON(data []byte) error {
 type Minute int
 
 func (m *Minute) UnmarshalJSON(in []byte) error {
-	data := string(in)
+	data := unquoteJSON(in)
 	if data == "Leaving" {
 		*m = Minute(0)
 		return nil
@@ -44,3 +43,17 @@ func (m *Minute) UnmarshalJSON(in []byte) error {
 	*m = Minute(val)
 	return nil
 }
+
+// unquoteJSON removes the quotes around a JSON string. The BART API sends most
+// values as strings, and encoding/json ignores the ",string" option on fields
+// whose type implements json.Unmarshaler, so these types get the quoted value
+// as is, and need to handle quoted and unquoted values alike.
+func unquoteJSON(in []byte) string {
+	data := string(in)
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		if val, err := strconv.Unquote(data); err == nil {
+			return val
+		}
+	}
+	return data
+}
@@ -1,6 +1,7 @@
 package bart
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -53,3 +54,27 @@ func (s stubHandler) makeHandler(t *testing.T) http.HandlerFunc {
 		fmt.Fprintf(w, "%s", data)
 	}
 }
+
+func TestUnmarshalJSON(t *testing.T) {
+	var out struct {
+		Flag    Bool   `json:",string"`
+		Minutes Minute `json:",string"`
+		Bare    Minute
+	}
+
+	err := json.Unmarshal([]byte(`{"flag":"1","minutes":"Leaving","bare":7}`), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Flag || out.Minutes != 0 || out.Bare != 7 {
+		t.Errorf("unexpected output, %+v", out)
+	}
+
+	err = json.Unmarshal([]byte(`{"flag":"0","minutes":"12"}`), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Flag || out.Minutes != 12 {
+		t.Errorf("unexpected output, %+v", out)
+	}
+}
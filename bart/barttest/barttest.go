@@ -0,0 +1,465 @@
+// Package barttest provides a fake BART API for tests, in the spirit of the
+// net/http/httptest package. A Server serves realistic responses for every cmd
+// supported by package bart, and can be scripted to fail, or to respond slowly.
+// It also records requests so tests can make assertions about them.
+//
+//	server := barttest.NewServer()
+//	defer server.Close()
+//
+//	client := server.Client(nil)
+//	res, err := client.RequestETD("mcar", "", "")
+package barttest
+
+import (
+	"embed"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Routes maps each cmd to the path of the route which serves it.
+var Routes = map[string]string{
+	"bsa":        "/bsa.aspx",
+	"count":      "/bsa.aspx",
+	"elev":       "/bsa.aspx",
+	"etd":        "/etd.aspx",
+	"routeinfo":  "/route.aspx",
+	"routes":     "/route.aspx",
+	"arrive":     "/sched.aspx",
+	"depart":     "/sched.aspx",
+	"fare":       "/sched.aspx",
+	"holiday":    "/sched.aspx",
+	"routesched": "/sched.aspx",
+	"scheds":     "/sched.aspx",
+	"special":    "/sched.aspx",
+	"stnsched":   "/sched.aspx",
+	"stnaccess":  "/stn.aspx",
+	"stninfo":    "/stn.aspx",
+	"stns":       "/stn.aspx",
+}
+
+// Fixture returns the default response body for cmd. It's the same body that a
+// Server responds with, unless it's been replaced with SetFixture. The routes
+// and stations are the snapshots from bart.Snapshot.
+func Fixture(cmd string) ([]byte, error) {
+	switch cmd {
+	case "routeinfo", "stns":
+		return bart.Snapshot(cmd)
+	}
+	return fixtures.ReadFile(path.Join("fixtures", cmd+".json"))
+}
+
+// A Server is a fake BART API, listening on a system-chosen port on the local
+// loopback interface. It's safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	latency  time.Duration
+	fixtures map[string][]byte
+	failures []scriptedFailure
+	requests []Request
+}
+
+// NewServer starts and returns a new Server. The caller should call Close when
+// finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{fixtures: make(map[string][]byte)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client makes a bart.Client which sends requests to the Server. The conf is
+// passed along to bart.NewClient, after pointing its BaseURL to the Server. If
+// conf.HTTP is nil, then it's set to an HTTP client configured for the Server.
+func (s *Server) Client(conf *bart.Config) *bart.Client {
+	if conf == nil {
+		conf = &bart.Config{}
+	}
+	conf.BaseURL = s.URL
+	if conf.HTTP == nil {
+		conf.HTTP = s.Server.Client()
+	}
+	return bart.NewClient(conf)
+}
+
+// SetLatency makes the Server wait for d before responding to each request.
+// The wait is cut short if the client gives up on the request.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// SetFixture replaces the response body for cmd. Pass in a nil body to go back
+// to the default fixture.
+func (s *Server) SetFixture(cmd string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if body == nil {
+		delete(s.fixtures, cmd)
+		return
+	}
+	s.fixtures[cmd] = body
+}
+
+// ErrorShape is a format of error response. The BART API has described errors
+// in each of these shapes at one point or another.
+type ErrorShape int
+
+const (
+	// ErrorXML is an XML document with the error text and details.
+	ErrorXML ErrorShape = iota
+	// ErrorJSONObject is a JSON object with the error text and details.
+	ErrorJSONObject
+	// ErrorJSONString is a JSON string with the error text only.
+	ErrorJSONString
+)
+
+// Failure describes an error response.
+type Failure struct {
+	Shape ErrorShape
+	// StatusCode is the HTTP status code of the response. If it's zero, then
+	// it's http.StatusOK, which is what the BART API usually does.
+	StatusCode int
+	Text       string
+	Details    string
+}
+
+type scriptedFailure struct {
+	cmd string
+	Failure
+}
+
+// FailNext makes the next requests for cmd fail, one per Failure, in order.
+// After that, requests for cmd succeed again. An empty cmd matches any cmd.
+func (s *Server) FailNext(cmd string, failures ...Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range failures {
+		s.failures = append(s.failures, scriptedFailure{cmd: cmd, Failure: f})
+	}
+}
+
+// A Request is a request received by the Server.
+type Request struct {
+	Path  string
+	Cmd   string
+	Query url.Values
+	Time  time.Time
+}
+
+// Requests lists the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Request, len(s.requests))
+	copy(out, s.requests)
+	return out
+}
+
+// Reset forgets about received requests, scripted failures, replaced fixtures
+// and latency.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = 0
+	s.fixtures = make(map[string][]byte)
+	s.failures = nil
+	s.requests = nil
+}
+
+// AssertRequested reports an error to t unless the Server has received a
+// request for cmd with all of the query params. Values of params are compared
+// case-insensitively, and other query params are ignored.
+func (s *Server) AssertRequested(t testing.TB, cmd string, params map[string]string) {
+	t.Helper()
+	requests := s.Requests()
+	for _, req := range requests {
+		if req.Cmd == cmd && queryMatches(req.Query, params) {
+			return
+		}
+	}
+	got := make([]string, len(requests))
+	for i, req := range requests {
+		got[i] = req.Path + "?" + req.Query.Encode()
+	}
+	t.Errorf("expected request for cmd %q with params %v; got %q", cmd, params, got)
+}
+
+// AssertRequestCount reports an error to t unless the Server has received n
+// requests for cmd. An empty cmd counts every request.
+func (s *Server) AssertRequestCount(t testing.TB, cmd string, n int) {
+	t.Helper()
+	var count int
+	for _, req := range s.Requests() {
+		if cmd == "" || req.Cmd == cmd {
+			count++
+		}
+	}
+	if count != n {
+		t.Errorf("wrong number of requests for cmd %q; got %d, expected %d", cmd, count, n)
+	}
+}
+
+func queryMatches(query url.Values, params map[string]string) bool {
+	for key, val := range params {
+		if !strings.EqualFold(query.Get(key), val) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	cmd := query.Get("cmd")
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Path: r.URL.Path, Cmd: cmd, Query: query, Time: time.Now()})
+	latency := s.latency
+	failure, failing := s.popFailure(cmd)
+	body, overridden := s.fixtures[cmd]
+	s.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timer.C:
+		}
+	}
+
+	switch {
+	case failing:
+		writeFailure(w, failure)
+		return
+	case query.Get("key") == "":
+		writeFailure(w, Failure{
+			Shape:   ErrorJSONObject,
+			Text:    "Invalid key",
+			Details: "The api key was missing or invalid.",
+		})
+		return
+	case Routes[cmd] == "" || !strings.HasSuffix(r.URL.Path, Routes[cmd]):
+		writeFailure(w, Failure{
+			Shape:   ErrorXML,
+			Text:    "Invalid cmd",
+			Details: fmt.Sprintf("The cmd parameter (%s) is missing or invalid. Please correct the error and try again.", cmd),
+		})
+		return
+	}
+
+	if !overridden {
+		var err error
+		if body, err = Fixture(cmd); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		switch cmd {
+		case "etd":
+			body = filterETD(body, query.Get("orig"))
+		case "bsa", "elev":
+			body = filterAdvisories(body, query.Get("orig"))
+		case "routeinfo":
+			body = filterRouteInfo(body, query.Get("route"))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(body)
+}
+
+// popFailure removes the next scripted failure for cmd. The caller must hold
+// the lock.
+func (s *Server) popFailure(cmd string) (Failure, bool) {
+	for i, f := range s.failures {
+		if f.cmd == "" || f.cmd == cmd {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			return f.Failure, true
+		}
+	}
+	return Failure{}, false
+}
+
+func writeFailure(w http.ResponseWriter, f Failure) {
+	status := f.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	var body []byte
+	switch f.Shape {
+	case ErrorXML:
+		var doc struct {
+			XMLName xml.Name `xml:"root"`
+			Text    string   `xml:"message>error>text"`
+			Details string   `xml:"message>error>details"`
+		}
+		doc.Text, doc.Details = f.Text, f.Details
+		out, _ := xml.MarshalIndent(doc, "", "\t")
+		body = append([]byte(xml.Header), out...)
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	case ErrorJSONString:
+		body = marshalError(f.Text)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	default:
+		body = marshalError(map[string]string{"text": f.Text, "details": f.Details})
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func marshalError(val interface{}) []byte {
+	var doc struct {
+		XML  map[string]string `json:"?xml"`
+		Root struct {
+			Message struct {
+				Error interface{} `json:"error"`
+			} `json:"message"`
+		} `json:"root"`
+	}
+	doc.XML = map[string]string{"@version": "1.0", "@encoding": "utf-8"}
+	doc.Root.Message.Error = val
+	out, _ := json.Marshal(doc)
+	return out
+}
+
+// filterETD narrows down the estimates in body to the orig station, the way the
+// BART API does. If there are no estimates for the station in body, then the
+// station is listed without any, along with a warning.
+func filterETD(body []byte, orig string) []byte {
+	if strings.EqualFold(orig, "ALL") {
+		return body
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return body
+	}
+	root, ok := doc["root"].(map[string]interface{})
+	if !ok {
+		return body
+	}
+	stations, _ := root["station"].([]interface{})
+
+	filtered := make([]interface{}, 0, 1)
+	for _, stn := range stations {
+		if m, ok := stn.(map[string]interface{}); ok && strings.EqualFold(fmt.Sprint(m["abbr"]), orig) {
+			filtered = append(filtered, stn)
+		}
+	}
+	if len(filtered) < 1 {
+		name := orig
+		if stn, err := bart.LookupStation(orig); err == nil {
+			name = stn.Name
+		}
+		filtered = append(filtered, map[string]interface{}{"name": name, "abbr": strings.ToUpper(orig)})
+		root["message"] = map[string]interface{}{"warning": "No data matched your criteria."}
+	}
+	root["station"] = filtered
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// filterRouteInfo narrows down the routes in body to the one with the route
+// number, the way the BART API does. Like the BART API, the one route is an
+// object rather than a list with one object.
+func filterRouteInfo(body []byte, route string) []byte {
+	if route == "" || strings.EqualFold(route, "all") {
+		return body
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return body
+	}
+	root, _ := doc["root"].(map[string]interface{})
+	routes, _ := root["routes"].(map[string]interface{})
+	if routes == nil {
+		return body
+	}
+	list, _ := routes["route"].([]interface{})
+
+	filtered := make([]interface{}, 0, 1)
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok && fmt.Sprint(m["number"]) == route {
+			filtered = append(filtered, item)
+		}
+	}
+	if len(filtered) == 1 {
+		routes["route"] = filtered[0]
+	} else {
+		routes["route"] = filtered
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// filterAdvisories narrows down the advisories in body to the ones for the orig
+// station, and the ones for the whole system.
+func filterAdvisories(body []byte, orig string) []byte {
+	if orig == "" {
+		return body
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return body
+	}
+	root, ok := doc["root"].(map[string]interface{})
+	if !ok {
+		return body
+	}
+	list, _ := root["bsa"].([]interface{})
+
+	filtered := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if stn := fmt.Sprint(m["station"]); strings.EqualFold(stn, orig) || strings.EqualFold(stn, "BART") {
+			filtered = append(filtered, item)
+		}
+	}
+	if len(filtered) < 1 {
+		filtered = append(filtered, map[string]interface{}{
+			"station":     "",
+			"description": map[string]interface{}{"#cdata-section": "No delays reported."},
+			"sms_text":    map[string]interface{}{"#cdata-section": "No delays reported."},
+		})
+	}
+	root["bsa"] = filtered
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return body
+	}
+	return out
+}
@@ -0,0 +1,281 @@
+package barttest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+	"github.com/rafaelespinoza/bart-go/bart/barttest"
+)
+
+func TestServerFixtures(t *testing.T) {
+	server := barttest.NewServer()
+	defer server.Close()
+	client := server.Client(nil)
+
+	t.Run("advisories", func(t *testing.T) {
+		bsa, err := client.RequestBSA()
+		if err != nil {
+			t.Error(err)
+		} else if len(bsa.Root.Data) < 1 || bsa.Root.Data[0].Description.Value == "" {
+			t.Error("expected advisories")
+		}
+		bsa, err = client.RequestStationBSA("12th")
+		if err != nil {
+			t.Error(err)
+		} else if len(bsa.Root.Data) != 1 || bsa.Root.Data[0].Type != bart.AdvisoryDelay {
+			t.Errorf("expected the system-wide delay, got %+v", bsa.Root.Data)
+		}
+		server.AssertRequested(t, "bsa", map[string]string{"orig": "12th"})
+		elev, err := client.RequestStationElevator("12th")
+		if err != nil {
+			t.Error(err)
+		} else if len(elev.Root.Data) != 1 || elev.Root.Data[0].Type != "" {
+			t.Errorf("expected no elevator outages at 12TH, got %+v", elev.Root.Data)
+		}
+		elev, err = client.RequestElevator()
+		if err != nil {
+			t.Error(err)
+		} else if len(elev.Root.Data) < 1 {
+			t.Error("expected elevator data")
+		}
+		count, err := client.RequestTrainCount()
+		if err != nil {
+			t.Error(err)
+		} else if count.Root.Data < 1 {
+			t.Error("expected train count")
+		}
+	})
+
+	t.Run("estimates", func(t *testing.T) {
+		all, err := client.RequestETD("ALL", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all.Root.Data) < 2 {
+			t.Errorf("expected several stations, got %d", len(all.Root.Data))
+		}
+
+		one, err := client.RequestETD("mcar", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(one.Root.Data) != 1 || one.Root.Data[0].Abbr != "MCAR" {
+			t.Fatalf("expected only MCAR, got %+v", one.Root.Data)
+		}
+		if len(one.Root.Data[0].Etds) < 1 || len(one.Root.Data[0].Etds[0].Estimates) < 1 {
+			t.Errorf("expected estimates for MCAR")
+		}
+
+		none, err := client.RequestETD("oakl", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(none.Root.Data) != 1 || len(none.Root.Data[0].Etds) != 0 {
+			t.Errorf("expected OAKL without estimates, got %+v", none.Root.Data)
+		}
+
+		server.AssertRequested(t, "etd", map[string]string{"orig": "mcar"})
+
+		board, err := client.RequestAllETDs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(board) != len(all.Root.Data) {
+			t.Errorf("wrong number of stations; got %d, expected %d", len(board), len(all.Root.Data))
+		}
+		if _, ok := board.NextTrain("12th", "antc"); !ok {
+			t.Error("expected a train from 12TH to ANTC")
+		}
+	})
+
+	t.Run("routes", func(t *testing.T) {
+		routes, err := client.RequestRoutes("")
+		if err != nil {
+			t.Error(err)
+		} else if len(routes.Root.Data.List) < 1 {
+			t.Error("expected routes")
+		}
+		info, err := client.RequestRoutesInfo("")
+		if err != nil {
+			t.Error(err)
+		} else if len(info.Root.Data.List) < 1 || len(info.Root.Data.List[0].Config.Stations) < 2 {
+			t.Error("expected route info")
+		}
+		server.AssertRequested(t, "routeinfo", map[string]string{"route": "all"})
+
+		one, err := client.RequestRouteInfo(bart.RouteParams{Route: 12, Sched: 72})
+		if err != nil {
+			t.Error(err)
+		} else if len(one.Root.Data.List) != 1 || one.Root.Data.List[0].Number != 12 {
+			t.Errorf("expected only route 12, got %+v", one.Root.Data.List)
+		}
+		server.AssertRequested(t, "routeinfo", map[string]string{"route": "12", "sched": "72"})
+	})
+
+	t.Run("schedules", func(t *testing.T) {
+		trips, err := client.RequestArrivals(bart.TripParams{Orig: "embr", Dest: "cols", Before: 2, After: 2})
+		if err != nil {
+			t.Error(err)
+		} else if len(trips.Root.Data.Request.List) < 1 {
+			t.Error("expected arrival trips")
+		}
+		trips, err = client.RequestDepartures(bart.TripParams{Orig: "sfia", Dest: "cast", Before: 2, After: 2})
+		if err != nil {
+			t.Error(err)
+		} else if len(trips.Root.Data.Request.List) < 1 || len(trips.Root.Data.Request.List[0].Legs) < 2 {
+			t.Error("expected departure trips with a transfer")
+		}
+		fare, err := client.RequestFare("12th", "embr", "")
+		if err != nil {
+			t.Error(err)
+		} else if fare.Clipper() <= 0 {
+			t.Error("expected fares")
+		}
+		holidays, err := client.RequestHolidaySchedules()
+		if err != nil {
+			t.Error(err)
+		} else if len(holidays.Root.Data) < 1 || len(holidays.Root.Data[0].List) < 1 {
+			t.Error("expected holidays")
+		}
+		scheds, err := client.RequestAvailableSchedules()
+		if err != nil {
+			t.Error(err)
+		} else if len(scheds.Root.Data.List) < 1 {
+			t.Error("expected available schedules")
+		}
+		special, err := client.RequestSpecialSchedules()
+		if err != nil {
+			t.Error(err)
+		} else if len(special.Root.Data.List) < 1 {
+			t.Error("expected special schedules")
+		}
+		stnsched, err := client.RequestStationSchedules("mcar", "")
+		if err != nil {
+			t.Error(err)
+		} else if len(stnsched.Root.Data.List) < 1 {
+			t.Error("expected station schedule")
+		}
+		routesched, err := client.RequestRouteSchedules(11, "", "", false)
+		if err != nil {
+			t.Error(err)
+		} else if len(routesched.Root.Data.List) < 1 || len(routesched.Root.Data.List[0].Stops) < 1 {
+			t.Error("expected route schedule")
+		} else if _, err = routesched.Root.Data.List[0].Stops[0].OrigTimeOn(routesched.Root.Date); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("stations", func(t *testing.T) {
+		stns, err := client.RequestStations()
+		if err != nil {
+			t.Error(err)
+		} else if len(stns.Root.Data.List) != len(bart.Stations()) {
+			t.Errorf("expected %d stations, got %d", len(bart.Stations()), len(stns.Root.Data.List))
+		}
+		info, err := client.RequestStationInfo("12th")
+		if err != nil {
+			t.Error(err)
+		} else if info.Root.Data.StationInfo.Abbr != "12TH" {
+			t.Error("expected station info")
+		}
+		access, err := client.RequestStationAccess("12th")
+		if err != nil {
+			t.Error(err)
+		} else if access.Root.Data.StationAccess.Abbr != "12TH" {
+			t.Error("expected station access")
+		}
+	})
+}
+
+func TestServerFailures(t *testing.T) {
+	server := barttest.NewServer()
+	defer server.Close()
+	client := server.Client(nil)
+
+	server.FailNext("stns",
+		barttest.Failure{Shape: barttest.ErrorXML, Text: "Invalid orig", Details: "xml"},
+		barttest.Failure{Shape: barttest.ErrorJSONObject, Text: "Invalid orig", Details: "object"},
+		barttest.Failure{Shape: barttest.ErrorJSONString, Text: "Invalid orig", StatusCode: http.StatusBadRequest},
+	)
+
+	for i, expectedDetails := range []string{"xml", "object", ""} {
+		_, err := client.RequestStations()
+		var apiErr *bart.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("request %d: expected %T, got %v", i, apiErr, err)
+		}
+		if !errors.Is(err, bart.ErrInvalidOrig) {
+			t.Errorf("request %d: expected error to match %v", i, bart.ErrInvalidOrig)
+		}
+		if apiErr.Details != expectedDetails {
+			t.Errorf("request %d: wrong Details; got %q, expected %q", i, apiErr.Details, expectedDetails)
+		}
+	}
+
+	if _, err := client.RequestStations(); err != nil {
+		t.Errorf("expected failures to be used up, got %v", err)
+	}
+	server.AssertRequestCount(t, "stns", 4)
+}
+
+func TestServerLatency(t *testing.T) {
+	server := barttest.NewServer()
+	defer server.Close()
+	client := server.Client(nil)
+
+	server.SetLatency(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.RequestTrainCountContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	server.Reset()
+	if _, err := client.RequestTrainCount(); err != nil {
+		t.Errorf("unexpected error after Reset, %v", err)
+	}
+	server.AssertRequestCount(t, "", 1)
+}
+
+func TestServerSetFixture(t *testing.T) {
+	server := barttest.NewServer()
+	defer server.Close()
+	client := server.Client(nil)
+
+	server.SetFixture("count", []byte(`{"root":{"traincount":"3","message":""}}`))
+	res, err := client.RequestTrainCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Root.Data != 3 {
+		t.Errorf("wrong train count; got %d, expected %d", res.Root.Data, 3)
+	}
+}
+
+func TestFixture(t *testing.T) {
+	for _, cmd := range []string{"routeinfo", "stns"} {
+		t.Run(cmd, func(t *testing.T) {
+			got, err := barttest.Fixture(cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := bart.Snapshot(cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, expected) {
+				t.Error("expected the fixture to be the bart snapshot")
+			}
+		})
+	}
+
+	if _, err := bart.Snapshot("etd"); err == nil {
+		t.Error("expected an error for a cmd without a snapshot")
+	}
+}
@@ -0,0 +1,85 @@
+package barttest_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+	"github.com/rafaelespinoza/bart-go/bart/barttest"
+)
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	server := barttest.NewServer()
+
+	// Record some responses from the fake server.
+	recorder := &barttest.Recorder{Dir: dir, Mode: barttest.Record, Ignore: []string{"date"}}
+	client := server.Client(&bart.Config{
+		Key:        "SECRET-KEY",
+		Middleware: []bart.Middleware{recorder.Middleware},
+	})
+	if _, err := client.RequestETD("mcar", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RequestStationSchedules("mcar", "10/17/2026"); err != nil {
+		t.Fatal(err)
+	}
+	server.SetFixture("count", []byte(`{"root":{"uri":{"#cdata-section":"http://api.bart.gov/api/bsa.aspx?cmd=count&key=SECRET-KEY&json=y"},"traincount":"42","message":""}}`))
+	if _, err := client.RequestTrainCount(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected %d recordings, got %d", 3, len(files))
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "SECRET-KEY") {
+			t.Errorf("expected key to be removed from %s", file.Name())
+		}
+	}
+
+	// Replay them without the fake server.
+	replayer := &barttest.Recorder{Dir: dir, Ignore: []string{"date"}}
+	client = bart.NewClient(&bart.Config{HTTP: &http.Client{Transport: replayer}})
+
+	etd, err := client.RequestETD("MCAR", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(etd.Root.Data) != 1 || etd.Root.Data[0].Abbr != "MCAR" {
+		t.Errorf("unexpected replayed response, %+v", etd.Root.Data)
+	}
+	count, err := client.RequestTrainCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count.Root.Data != 42 {
+		t.Errorf("wrong train count; got %d, expected %d", count.Root.Data, 42)
+	}
+	// The date param is ignored, so any date matches.
+	if _, err = client.RequestStationSchedules("mcar", "10/18/2026"); err != nil {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	_, err = client.RequestETD("embr", "", "")
+	var unmatched *barttest.UnmatchedRequestError
+	if !errors.As(err, &unmatched) {
+		t.Fatalf("expected %T, got %v", unmatched, err)
+	}
+	if !strings.Contains(unmatched.Request, "orig=embr") || strings.Contains(unmatched.Request, "key=") {
+		t.Errorf("unexpected Request, %q", unmatched.Request)
+	}
+}
@@ -0,0 +1,148 @@
+package bart
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// A Cache stores response bodies from the BART API. The key is the full URL of
+// the request, including the encoded query, but without the API key. Only
+// successful responses are stored. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value for key, if it's present and not expired.
+	Get(key string) (val []byte, ok bool)
+	// Set stores val for key, and expires it after ttl.
+	Set(key string, val []byte, ttl time.Duration)
+}
+
+// DefaultCacheTTL is how long to keep a response to the cmd at route in a
+// Cache. Real-time estimates and advisories expire after seconds, station and
+// route information after hours, and holiday and available schedules after a
+// day. A zero value means the response should not be cached.
+func DefaultCacheTTL(route, cmd string) time.Duration {
+	return defaultCacheTTLs[route+"?cmd="+cmd]
+}
+
+var defaultCacheTTLs = map[string]time.Duration{
+	"/bsa.aspx?cmd=bsa":          30 * time.Second,
+	"/bsa.aspx?cmd=count":        30 * time.Second,
+	"/bsa.aspx?cmd=elev":         5 * time.Minute,
+	"/etd.aspx?cmd=etd":          15 * time.Second,
+	"/route.aspx?cmd=routeinfo":  6 * time.Hour,
+	"/route.aspx?cmd=routes":     6 * time.Hour,
+	"/sched.aspx?cmd=arrive":     time.Minute,
+	"/sched.aspx?cmd=depart":     time.Minute,
+	"/sched.aspx?cmd=fare":       24 * time.Hour,
+	"/sched.aspx?cmd=holiday":    24 * time.Hour,
+	"/sched.aspx?cmd=routesched": time.Hour,
+	"/sched.aspx?cmd=scheds":     24 * time.Hour,
+	"/sched.aspx?cmd=special":    time.Hour,
+	"/sched.aspx?cmd=stnsched":   time.Hour,
+	"/stn.aspx?cmd=stnaccess":    6 * time.Hour,
+	"/stn.aspx?cmd=stninfo":      6 * time.Hour,
+	"/stn.aspx?cmd=stns":         6 * time.Hour,
+}
+
+// cacheTTL is how long to cache a response to the cmd at route.
+func (c *Config) cacheTTL(route, cmd string) time.Duration {
+	if c.Cache == nil {
+		return 0
+	}
+	if c.CacheTTL != nil {
+		return c.CacheTTL(route, cmd)
+	}
+	return DefaultCacheTTL(route, cmd)
+}
+
+// LRUCache is an in-memory Cache. When it's full, the least recently used entry
+// is evicted to make room for a new one.
+type LRUCache struct {
+	mu      sync.Mutex
+	size    int
+	entries *list.List
+	items   map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	val     []byte
+	expires time.Time
+}
+
+// NewLRUCache makes an LRUCache which holds up to size entries. A size less than
+// 1 means there is no limit.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:    size,
+		entries: list.New(),
+		items:   make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !time.Now().Before(entry.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.entries.MoveToFront(elem)
+	return entry.val, true
+}
+
+func (c *LRUCache) Set(key string, val []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &lruEntry{key: key, val: val, expires: time.Now().Add(ttl)}
+	if elem, ok := c.items[key]; ok {
+		elem.Value = entry
+		c.entries.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.entries.PushFront(entry)
+
+	for c.size > 0 && c.entries.Len() > c.size {
+		c.remove(c.entries.Back())
+	}
+}
+
+// Len is the number of entries in the cache, including expired entries which
+// have not been evicted yet.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Len()
+}
+
+func (c *LRUCache) remove(elem *list.Element) {
+	c.entries.Remove(elem)
+	delete(c.items, elem.Value.(*lruEntry).key)
+}
+
+// markCached sets the Cached field of the ResponseMetaData in out, which should
+// be a pointer to one of the response types in this package. Other values are
+// left alone.
+func markCached(out interface{}) {
+	val := reflect.ValueOf(out)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return
+	}
+	root := val.Elem().FieldByName("Root")
+	if !root.IsValid() || root.Kind() != reflect.Struct {
+		return
+	}
+	meta := root.FieldByName("ResponseMetaData")
+	if !meta.IsValid() || meta.Type() != reflect.TypeOf(ResponseMetaData{}) {
+		return
+	}
+	meta.FieldByName("Cached").SetBool(true)
+}
@@ -0,0 +1,109 @@
+package bart
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	t.Run("evicts least recently used", func(t *testing.T) {
+		cache := NewLRUCache(2)
+		cache.Set("a", []byte("A"), time.Minute)
+		cache.Set("b", []byte("B"), time.Minute)
+		if _, ok := cache.Get("a"); !ok {
+			t.Fatal("expected a to be present")
+		}
+		cache.Set("c", []byte("C"), time.Minute)
+
+		if _, ok := cache.Get("b"); ok {
+			t.Error("expected b to be evicted")
+		}
+		for _, key := range []string{"a", "c"} {
+			if _, ok := cache.Get(key); !ok {
+				t.Errorf("expected %s to be present", key)
+			}
+		}
+		if cache.Len() != 2 {
+			t.Errorf("wrong Len; got %d, expected %d", cache.Len(), 2)
+		}
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		cache := NewLRUCache(0)
+		cache.Set("a", []byte("A"), time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		if _, ok := cache.Get("a"); ok {
+			t.Error("expected a to be expired")
+		}
+		if cache.Len() != 0 {
+			t.Errorf("wrong Len; got %d, expected %d", cache.Len(), 0)
+		}
+	})
+}
+
+func TestRequestAPICache(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"root":{"stations":{"station":[{"name":"12th St. Oakland City Center","abbr":"12TH"}]},"message":""}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Cache: NewLRUCache(10)})
+	client.conf.BaseURL = server.URL
+
+	first, err := client.RequestStations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Root.Cached {
+		t.Error("did not expect first response to be cached")
+	}
+
+	second, err := client.RequestStations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Root.Cached {
+		t.Error("expected second response to be cached")
+	}
+	if len(second.Root.Data.List) != 1 || second.Root.Data.List[0].Abbr != "12TH" {
+		t.Errorf("unexpected cached response, %+v", second.Root.Data)
+	}
+	if calls != 1 {
+		t.Errorf("wrong number of calls; got %d, expected %d", calls, 1)
+	}
+
+	// The API key isn't part of the cache key, so a client with another key
+	// shares the responses.
+	other := NewClient(&Config{Key: "other-key", Cache: client.conf.Cache})
+	other.conf.BaseURL = server.URL
+	third, err := other.RequestStations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !third.Root.Cached {
+		t.Error("expected response for another key to be cached")
+	}
+	if calls != 1 {
+		t.Errorf("wrong number of calls; got %d, expected %d", calls, 1)
+	}
+	for key := range client.conf.Cache.(*LRUCache).items {
+		if strings.Contains(key, "key=") {
+			t.Errorf("expected cache key without the API key, got %q", key)
+		}
+	}
+
+	// Responses with a zero TTL are not cached.
+	client.conf.CacheTTL = func(route, cmd string) time.Duration { return 0 }
+	if _, err = client.RequestStations(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("wrong number of calls; got %d, expected %d", calls, 2)
+	}
+}
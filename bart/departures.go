@@ -0,0 +1,168 @@
+package bart
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// etdAllStations is the orig value for requesting estimates at every station.
+const etdAllStations = "ALL"
+
+// normalizeETDOrig is like normalizeStation, but also accepts "ALL" in any case
+// for the departures at every station.
+func normalizeETDOrig(orig string) (string, error) {
+	if strings.EqualFold(strings.TrimSpace(orig), etdAllStations) {
+		return etdAllStations, nil
+	}
+	return normalizeStation("orig", orig)
+}
+
+// RequestAllETDs requests estimated departures for every station at once, and
+// indexes them by station. It's the same as RequestETD with "ALL" for orig, but
+// easier to look up. See official docs at
+// https://api.bart.gov/docs/etd/etd.aspx.
+func (a *EstimatesAPI) RequestAllETDs() (out ETDBoard, err error) {
+	return a.RequestAllETDsContext(context.Background())
+}
+
+// RequestAllETDsContext is like RequestAllETDs, but uses ctx for the request.
+func (a *EstimatesAPI) RequestAllETDsContext(ctx context.Context) (out ETDBoard, err error) {
+	res, err := a.RequestETDContext(ctx, etdAllStations, "", "")
+	if err != nil {
+		return
+	}
+	out = NewETDBoard(res)
+	return
+}
+
+// ETDBoard is the estimated departures at many stations. Keys are upper-case
+// station abbreviations, such as "MCAR".
+type ETDBoard map[string]StationETDs
+
+// NewETDBoard indexes the stations in res by abbreviation.
+func NewETDBoard(res EstimatesResponse) ETDBoard {
+	out := make(ETDBoard, len(res.Root.Data))
+	for _, stn := range res.Root.Data {
+		out[strings.ToUpper(stn.Abbr)] = stn
+	}
+	return out
+}
+
+// Station looks up a station by its abbreviation, ignoring case. The second
+// output is false if there's no such station on the board.
+func (b ETDBoard) Station(abbr string) (StationETDs, bool) {
+	stn, ok := b[strings.ToUpper(strings.TrimSpace(abbr))]
+	return stn, ok
+}
+
+// Departures lists the departures at a station, sorted by minutes. It's empty
+// if there's no such station on the board.
+func (b ETDBoard) Departures(abbr string) Departures {
+	stn, _ := b.Station(abbr)
+	return stn.Departures().SortByMinutes()
+}
+
+// NextTrain finds the soonest departure from orig to dest. Both are station
+// abbreviations. See Departures.NextTrain for what counts as stopping at dest.
+func (b ETDBoard) NextTrain(orig, dest string) (Departure, bool) {
+	stn, _ := b.Station(orig)
+	return stn.Departures().NextTrain(dest)
+}
+
+// Departure is an Estimate along with the station it's leaving from and where
+// the train is going.
+type Departure struct {
+	Estimate
+	// Station is the abbreviation of the station the train is leaving from.
+	Station string
+	// Destination is the name of the last station of the train.
+	Destination string
+	// Abbreviation is the abbreviation of the last station of the train.
+	Abbreviation string
+	Limited      string
+}
+
+// Departures is a list of Departure values, with helpers for finding the ones
+// you want.
+type Departures []Departure
+
+// Departures flattens the estimates at the station into one list, in the same
+// order as the response.
+func (s StationETDs) Departures() Departures {
+	var out Departures
+	for _, etd := range s.Etds {
+		for _, est := range etd.Estimates {
+			out = append(out, Departure{
+				Estimate:     est,
+				Station:      s.Abbr,
+				Destination:  etd.Destination,
+				Abbreviation: etd.Abbreviation,
+				Limited:      etd.Limited,
+			})
+		}
+	}
+	return out
+}
+
+// DepartureFilter says which departures to keep. Zero-value fields match any
+// departure. Direction is "North" or "South", and Color is a line color, such
+// as "YELLOW". Both are compared case-insensitively.
+type DepartureFilter struct {
+	Platform  int
+	Direction string
+	Color     string
+}
+
+func (f DepartureFilter) matches(d Departure) bool {
+	if f.Platform != 0 && f.Platform != d.Platform {
+		return false
+	}
+	if f.Direction != "" && !strings.EqualFold(f.Direction, d.Direction) {
+		return false
+	}
+	if f.Color != "" && !strings.EqualFold(f.Color, d.Color) {
+		return false
+	}
+	return true
+}
+
+// Filter makes a new list of the departures that match f.
+func (d Departures) Filter(f DepartureFilter) Departures {
+	out := make(Departures, 0, len(d))
+	for _, dep := range d {
+		if f.matches(dep) {
+			out = append(out, dep)
+		}
+	}
+	return out
+}
+
+// SortByMinutes makes a new list of the departures, sorted by minutes until
+// departure. Departures leaving at the same time keep their order.
+func (d Departures) SortByMinutes() Departures {
+	out := make(Departures, len(d))
+	copy(out, d)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Minutes < out[j].Minutes })
+	return out
+}
+
+// NextTrain finds the soonest departure which stops at dest, a station
+// abbreviation such as "ANTC". A train stops at dest if it's the last station
+// of the train, or if it's between the departure's Station and the last station
+// on a route of the same color in DefaultNetwork. The second output is false if
+// there's no such departure.
+func (d Departures) NextTrain(dest string) (out Departure, ok bool) {
+	dest = strings.ToUpper(strings.TrimSpace(dest))
+	network := DefaultNetwork()
+	for _, dep := range d {
+		terminal := strings.ToUpper(dep.Abbreviation)
+		if terminal != dest && !network.stopsAt(strings.ToUpper(dep.Station), dest, terminal, dep.Color) {
+			continue
+		}
+		if !ok || dep.Minutes < out.Minutes {
+			out, ok = dep, true
+		}
+	}
+	return
+}
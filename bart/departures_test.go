@@ -0,0 +1,142 @@
+package bart
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const testETDs = `{"root":{"station":[
+	{"name":"MacArthur","abbr":"MCAR","etd":[
+		{"destination":"Antioch","abbreviation":"ANTC","limited":"0","estimate":[
+			{"minutes":"12","platform":"3","direction":"North","color":"YELLOW"},
+			{"minutes":"Leaving","platform":"3","direction":"North","color":"YELLOW"}
+		]},
+		{"destination":"Richmond","abbreviation":"RICH","limited":"0","estimate":[
+			{"minutes":"4","platform":"4","direction":"North","color":"ORANGE"}
+		]},
+		{"destination":"Millbrae","abbreviation":"MLBR","limited":"0","estimate":[
+			{"minutes":"7","platform":"2","direction":"South","color":"RED"},
+			{"minutes":"4","platform":"2","direction":"South","color":"RED"}
+		]}
+	]},
+	{"name":"Oakland Int'l Airport","abbr":"OAKL"}
+],"message":""}}`
+
+func TestNormalizeETDOrig(t *testing.T) {
+	for _, in := range []string{"ALL", "all", " All "} {
+		got, err := normalizeETDOrig(in)
+		if err != nil {
+			t.Errorf("unexpected error for %q; %v", in, err)
+		}
+		if got != etdAllStations {
+			t.Errorf("wrong orig; got %q, expected %q", got, etdAllStations)
+		}
+	}
+
+	if got, err := normalizeETDOrig("MCar"); err != nil || got != "mcar" {
+		t.Errorf("wrong orig; got %q, %v, expected %q", got, err, "mcar")
+	}
+	var stnErr *InvalidStationError
+	if _, err := normalizeETDOrig("alll"); !errors.As(err, &stnErr) {
+		t.Errorf("wrong error type; got %T, expected %T", err, stnErr)
+	}
+}
+
+func TestETDBoard(t *testing.T) {
+	var res EstimatesResponse
+	if err := json.Unmarshal([]byte(testETDs), &res); err != nil {
+		t.Fatal(err)
+	}
+	board := NewETDBoard(res)
+
+	t.Run("Station", func(t *testing.T) {
+		if _, ok := board.Station("mcar"); !ok {
+			t.Error("expected to find MCAR")
+		}
+		if stn, ok := board.Station("OAKL"); !ok || len(stn.Departures()) != 0 {
+			t.Errorf("expected OAKL without departures, got %+v", stn)
+		}
+		if _, ok := board.Station("embr"); ok {
+			t.Error("did not expect to find EMBR")
+		}
+	})
+
+	t.Run("Departures", func(t *testing.T) {
+		got := board.Departures("mcar")
+		expected := []string{"ANTC", "RICH", "MLBR", "MLBR", "ANTC"}
+		if len(got) != len(expected) {
+			t.Fatalf("wrong number of departures; got %d, expected %d", len(got), len(expected))
+		}
+		for i, dep := range got {
+			if dep.Abbreviation != expected[i] {
+				t.Errorf("item %d; wrong Abbreviation; got %q, expected %q", i, dep.Abbreviation, expected[i])
+			}
+			if dep.Station != "MCAR" {
+				t.Errorf("item %d; wrong Station; got %q, expected %q", i, dep.Station, "MCAR")
+			}
+			if i > 0 && dep.Minutes < got[i-1].Minutes {
+				t.Errorf("item %d; not sorted by minutes", i)
+			}
+		}
+		if len(board.Departures("embr")) != 0 {
+			t.Error("expected no departures for unknown station")
+		}
+	})
+
+	t.Run("NextTrain", func(t *testing.T) {
+		dep, ok := board.NextTrain("MCAR", "mlbr")
+		if !ok {
+			t.Fatal("expected a train to MLBR")
+		}
+		if dep.Minutes != 4 || dep.Destination != "Millbrae" {
+			t.Errorf("wrong departure; got %+v", dep)
+		}
+		if _, ok = board.NextTrain("MCAR", "dubl"); ok {
+			t.Error("did not expect a train to DUBL")
+		}
+
+		// These are stations on the way to the last station of the train.
+		for dest, expected := range map[string]string{
+			"12th": "MLBR",
+			"ashb": "RICH",
+			"pitt": "ANTC",
+		} {
+			dep, ok = board.NextTrain("mcar", dest)
+			if !ok {
+				t.Errorf("expected a train stopping at %s", dest)
+			} else if dep.Abbreviation != expected {
+				t.Errorf("wrong train to %s; got %q, expected %q", dest, dep.Abbreviation, expected)
+			}
+		}
+		if dep, ok = board.NextTrain("mcar", "wcrk"); !ok || dep.Minutes != 0 {
+			t.Errorf("expected the train which is leaving, got %+v", dep)
+		}
+		if _, ok = board.NextTrain("MCAR", "frmt"); ok {
+			t.Error("did not expect a train stopping at FRMT")
+		}
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		deps := board.Departures("MCAR")
+		tests := []struct {
+			name     string
+			filter   DepartureFilter
+			expected int
+		}{
+			{name: "everything", filter: DepartureFilter{}, expected: 5},
+			{name: "platform", filter: DepartureFilter{Platform: 3}, expected: 2},
+			{name: "direction", filter: DepartureFilter{Direction: "north"}, expected: 3},
+			{name: "color", filter: DepartureFilter{Color: "red"}, expected: 2},
+			{name: "combined", filter: DepartureFilter{Direction: "North", Color: "ORANGE"}, expected: 1},
+			{name: "nothing", filter: DepartureFilter{Platform: 1}, expected: 0},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				if got := deps.Filter(test.filter); len(got) != test.expected {
+					t.Errorf("wrong number of departures; got %d, expected %d", len(got), test.expected)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package bart
+
+import (
+	"fmt"
+	"strings"
+)
+
+// APIError is an error reported by the BART API. The API describes errors in a
+// few different shapes (XML, a JSON object or a plain JSON string), but they
+// all end up here. Transport failures, such as a refused connection, are not
+// APIErrors.
+type APIError struct {
+	// Text is a short description of the error, such as "Invalid orig".
+	Text string
+	// Details is a longer explanation of the error, if BART provided one.
+	Details string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Route is the path of the request, such as "/etd.aspx".
+	Route string
+	// Cmd is the value of the cmd query parameter of the request.
+	Cmd string
+	// Body is the raw response body.
+	Body []byte
+}
+
+// Sentinel errors for common API errors. Use them with errors.Is, which
+// compares the Text of an *APIError to the sentinel, ignoring case.
+var (
+	ErrInvalidCmd   = &APIError{Text: "Invalid cmd"}
+	ErrInvalidKey   = &APIError{Text: "Invalid key"}
+	ErrInvalidOrig  = &APIError{Text: "Invalid orig"}
+	ErrInvalidDest  = &APIError{Text: "Invalid dest"}
+	ErrInvalidRoute = &APIError{Text: "Invalid route"}
+	ErrInvalidSched = &APIError{Text: "Invalid sched"}
+)
+
+func (e *APIError) Error() string {
+	msg := e.Text
+	if e.Details != "" {
+		msg = fmt.Sprintf("%s. %s", msg, e.Details)
+	}
+	if e.Cmd == "" && e.StatusCode == 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s?cmd=%s (status %d): %s", e.Route, e.Cmd, e.StatusCode, msg)
+}
+
+// InvalidRouteError is returned when a route number is out of range. When it is
+// returned by a request method, no request was made. It matches
+// ErrInvalidRoute when using errors.Is.
+type InvalidRouteError struct {
+	// Route is the input value.
+	Route int
+}
+
+func (e *InvalidRouteError) Error() string {
+	return fmt.Sprintf("invalid route %d", e.Route)
+}
+
+func (e *InvalidRouteError) Is(target error) bool {
+	return target == ErrInvalidRoute
+}
+
+// InvalidSchedError is returned when a schedule number is out of range. When it
+// is returned by a request method, no request was made. It matches
+// ErrInvalidSched when using errors.Is.
+type InvalidSchedError struct {
+	// Sched is the input value.
+	Sched int
+}
+
+func (e *InvalidSchedError) Error() string {
+	return fmt.Sprintf("invalid sched %d", e.Sched)
+}
+
+func (e *InvalidSchedError) Is(target error) bool {
+	return target == ErrInvalidSched
+}
+
+// Is reports whether target is an *APIError with the same Text, ignoring case.
+// Other fields are not compared, so the sentinel errors in this package match
+// any error with the same Text.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok || t.Text == "" {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(e.Text), t.Text)
+}
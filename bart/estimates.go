@@ -1,17 +1,22 @@
 package bart
 
+import "context"
+
 // EstimatesAPI is a namespace for real-time information requests to /etd.aspx.
 // See official docs at https://api.bart.gov/docs/etd/.
 type EstimatesAPI struct {
 	conf *Config
 }
 
-func initEstimatesRequest(orig, plat, dir string) (out apiRequest) {
+func initEstimatesRequest(orig, plat, dir string) (out apiRequest, err error) {
 	p := EstimateParams{
 		Orig: orig,
 		Plat: plat,
 		Dir:  dir,
 	}
+	if p.Orig, err = normalizeETDOrig(p.Orig); err != nil {
+		return
+	}
 	out.route = "/etd.aspx"
 	out.cmd = "etd"
 
@@ -33,14 +38,22 @@ func (a *EstimatesAPI) clientConf() *Config {
 }
 
 // RequestETD requests estimated departure time for specified station. The orig
-// param must be a 4-letter abbreviation for a station name. Specify plat "1",
-// "2", "3", "4" for a specific platform, or an empty string for all platforms.
-// Specify dir "n" for north, "s" for south, or you can pass empty string to get
-// both directions.  See official docs at
+// param must be a 4-letter abbreviation for a station name, or "ALL" for every
+// station. Specify plat "1", "2", "3", "4" for a specific platform, or an empty
+// string for all platforms. Specify dir "n" for north, "s" for south, or you
+// can pass empty string to get both directions.  See official docs at
 // https://api.bart.gov/docs/etd/etd.aspx.
 func (a *EstimatesAPI) RequestETD(orig, plat, dir string) (res EstimatesResponse, err error) {
-	params := initEstimatesRequest(orig, plat, dir)
-	err = params.requestAPI(a, &res)
+	return a.RequestETDContext(context.Background(), orig, plat, dir)
+}
+
+// RequestETDContext is like RequestETD, but uses ctx for the request.
+func (a *EstimatesAPI) RequestETDContext(ctx context.Context, orig, plat, dir string) (res EstimatesResponse, err error) {
+	params, err := initEstimatesRequest(orig, plat, dir)
+	if err != nil {
+		return
+	}
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -58,36 +71,54 @@ type EstimateParams struct {
 // the RequestETD method except it takes an EstimateParams value. See official
 // docs at https://api.bart.gov/docs/etd/etd.aspx.
 func (a *EstimatesAPI) RequestEstimate(p EstimateParams) (res EstimatesResponse, err error) {
-	params := initEstimatesRequest(p.Orig, p.Plat, p.Dir)
-	err = params.requestAPI(a, &res)
+	return a.RequestEstimateContext(context.Background(), p)
+}
+
+// RequestEstimateContext is like RequestEstimate, but uses ctx for the request.
+func (a *EstimatesAPI) RequestEstimateContext(ctx context.Context, p EstimateParams) (res EstimatesResponse, err error) {
+	params, err := initEstimatesRequest(p.Orig, p.Plat, p.Dir)
+	if err != nil {
+		return
+	}
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
-// EstimatesResponse is the shape of an API response. One field, under the
-// Estimates key is of the private type, estiMinute. It's there because
-// zero-value is not "0", but "Leaving". To make it easier to deserialize, this
-// package aliases "Leaving" to int 0.
+// EstimatesResponse is the shape of an API response.
 type EstimatesResponse struct {
 	Root struct {
 		ResponseMetaData
-		Data []struct {
-			Name string
-			Abbr string
-			Etds []struct {
-				Destination  string
-				Abbreviation string
-				Limited      string
-				Estimates    []struct {
-					Minutes   Minute `json:",string"`
-					Platform  int    `json:",string"`
-					Direction string
-					Length    int `json:",string"`
-					Color     string
-					Hexcolor  string
-					BikeFlag  Bool `json:",string"`
-					Delay     int  `json:",string"`
-				} `json:"estimate"`
-			} `json:"etd"`
-		} `json:"station"`
+		Data []StationETDs `json:"station"`
 	}
 }
+
+// StationETDs is a station and its estimated departures, grouped by
+// destination.
+type StationETDs struct {
+	Name string
+	Abbr string
+	Etds []ETD `json:"etd"`
+}
+
+// ETD is the estimated departures from a station to one destination.
+type ETD struct {
+	Destination  string
+	Abbreviation string
+	Limited      string
+	Estimates    []Estimate `json:"estimate"`
+}
+
+// Estimate is the estimated departure of one train. The Minutes field is of
+// the type, Minute. It's there because zero-value is not "0", but "Leaving". To
+// make it easier to deserialize, this package aliases "Leaving" to int 0.
+type Estimate struct {
+	Minutes    Minute `json:",string"`
+	Platform   int    `json:",string"`
+	Direction  string
+	Length     int `json:",string"`
+	Color      string
+	Hexcolor   string
+	BikeFlag   Bool `json:",string"`
+	Delay      int  `json:",string"`
+	CancelFlag Bool `json:",string"`
+}
@@ -29,6 +29,20 @@ func ExampleClient() {
 		HTTP: &http.Client{
 			Transport: &demo{},
 		},
+
+		// point to a self-hosted mirror, optional.
+		BaseURL: "http://localhost:8080/api",
+
+		// wrap the HTTP transport, optional.
+		Middleware: []bart.Middleware{
+			func(next http.RoundTripper) http.RoundTripper {
+				return bart.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					req = req.Clone(req.Context())
+					req.Header.Set("User-Agent", "my-app")
+					return next.RoundTrip(req)
+				})
+			},
+		},
 	}
 	client = bart.NewClient(conf)
 
@@ -8,7 +8,7 @@ import (
 )
 
 func ExampleAdvisoriesAPI() {
-	client := bart.NewClient(nil)
+	client := exampleServer.Client(nil)
 	var res interface{}
 	var err error
 
@@ -40,7 +40,7 @@ func ExampleAdvisoriesAPI() {
 }
 
 func ExampleRoutesAPI() {
-	client := bart.NewClient(nil)
+	client := exampleServer.Client(nil)
 	var res interface{}
 	var err error
 
@@ -72,7 +72,7 @@ func ExampleRoutesAPI() {
 }
 
 func ExampleStationsAPI() {
-	client := bart.NewClient(nil)
+	client := exampleServer.Client(nil)
 	var res interface{}
 	var err error
 
@@ -113,7 +113,7 @@ func ExampleStationsAPI() {
 }
 
 func ExampleEstimatesAPI() {
-	client := bart.NewClient(nil)
+	client := exampleServer.Client(nil)
 	var res interface{}
 	var err error
 
@@ -153,7 +153,7 @@ func ExampleEstimatesAPI() {
 }
 
 func ExampleSchedulesAPI() {
-	client := bart.NewClient(nil)
+	client := exampleServer.Client(nil)
 	var res interface{}
 	var err error
 
@@ -198,6 +198,13 @@ func ExampleSchedulesAPI() {
 	}
 	fmt.Printf("%T\n", res)
 
+	// Get the fares for a trip.
+	res, err = client.RequestFare("12th", "embr", "")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("%T\n", res)
+
 	// Get info on upcoming BART holidays and what schedules run on those days.
 	res, err = client.RequestHolidaySchedules()
 	if err != nil {
@@ -238,6 +245,7 @@ func ExampleSchedulesAPI() {
 	// bart.TripsResponse
 	// bart.AvailableSchedulesResponse
 	// bart.TripsResponse
+	// bart.FareResponse
 	// bart.HolidaySchedulesResponse
 	// bart.RouteSchedulesResponse
 	// bart.SpecialSchedulesResponse
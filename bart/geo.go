@@ -0,0 +1,99 @@
+package bart
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// earthRadius is the mean radius of the Earth, in meters.
+const earthRadius = 6371008.8
+
+// A StationIndex finds stations by location. Make one from the stations in a
+// response to RequestStations with NewStationIndex, or use the bundled
+// snapshot with DefaultStationIndex. There are few enough stations that every
+// lookup checks each of them. A StationIndex is safe for concurrent use, since
+// it's never modified after it's made.
+type StationIndex struct {
+	stations []StationSummary
+}
+
+// StationDistance is a station and its distance from a location.
+type StationDistance struct {
+	StationSummary
+	Meters float64
+}
+
+// NewStationIndex makes a StationIndex of stations, such as the ones at
+// StationsResponse.Root.Data.List.
+func NewStationIndex(stations []StationSummary) *StationIndex {
+	return &StationIndex{stations: append([]StationSummary(nil), stations...)}
+}
+
+var (
+	defaultStationIndex     *StationIndex
+	defaultStationIndexOnce sync.Once
+)
+
+// DefaultStationIndex is a StationIndex built from a snapshot of the stations
+// bundled with this package.
+func DefaultStationIndex() *StationIndex {
+	defaultStationIndexOnce.Do(func() {
+		var res StationsResponse
+		data, err := Snapshot("stns")
+		if err == nil {
+			err = json.Unmarshal(data, &res)
+		}
+		if err != nil {
+			panic(fmt.Errorf("bart: invalid stations snapshot: %w", err))
+		}
+		defaultStationIndex = NewStationIndex(res.Root.Data.List)
+	})
+	return defaultStationIndex
+}
+
+// Nearest lists up to n stations closest to the location, closest first. The
+// lat and lon are in degrees.
+func (x *StationIndex) Nearest(lat, lon float64, n int) []StationDistance {
+	if n <= 0 {
+		return nil
+	}
+	out := x.byDistance(lat, lon)
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// WithinRadius lists the stations within meters of the location, closest
+// first. The lat and lon are in degrees.
+func (x *StationIndex) WithinRadius(lat, lon, meters float64) []StationDistance {
+	all := x.byDistance(lat, lon)
+	n := sort.Search(len(all), func(i int) bool { return all[i].Meters > meters })
+	return all[:n]
+}
+
+func (x *StationIndex) byDistance(lat, lon float64) []StationDistance {
+	out := make([]StationDistance, len(x.stations))
+	for i, stn := range x.stations {
+		out[i] = StationDistance{
+			StationSummary: stn,
+			Meters:         haversine(lat, lon, float64(stn.Latitude), float64(stn.Longitude)),
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Meters < out[j].Meters })
+	return out
+}
+
+// haversine is the great-circle distance in meters between two points, given
+// in degrees.
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const toRadians = math.Pi / 180
+	dLat := (lat2 - lat1) * toRadians
+	dLon := (lon2 - lon1) * toRadians
+	a := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(lat1*toRadians)*math.Cos(lat2*toRadians)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,77 @@
+package bart
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		expected               float64
+	}{
+		{name: "same point", lat1: 37.8, lon1: -122.27, lat2: 37.8, lon2: -122.27, expected: 0},
+		{name: "one degree of longitude at the equator", lat1: 0, lon1: 0, lat2: 0, lon2: 1, expected: 111195},
+		{name: "12TH to 19TH", lat1: 37.803768, lon1: -122.271450, lat2: 37.808350, lon2: -122.268602, expected: 567.6},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := haversine(test.lat1, test.lon1, test.lat2, test.lon2)
+			if math.Abs(got-test.expected) > 1 {
+				t.Errorf("wrong distance; got %.1f, expected %.1f", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestStationIndex(t *testing.T) {
+	index := DefaultStationIndex()
+	// The Ferry Building, near Embarcadero station.
+	const lat, lon = 37.7955, -122.3937
+
+	t.Run("Nearest", func(t *testing.T) {
+		got := index.Nearest(lat, lon, 3)
+		expected := []string{"EMBR", "MONT", "POWL"}
+		if len(got) != len(expected) {
+			t.Fatalf("wrong number of stations; got %d, expected %d", len(got), len(expected))
+		}
+		for i, stn := range got {
+			if stn.Abbr != expected[i] {
+				t.Errorf("item %d; wrong Abbr; got %q, expected %q", i, stn.Abbr, expected[i])
+			}
+			if i > 0 && stn.Meters < got[i-1].Meters {
+				t.Errorf("item %d; not sorted by distance", i)
+			}
+		}
+		if got := index.Nearest(lat, lon, 0); len(got) != 0 {
+			t.Errorf("expected no stations, got %d", len(got))
+		}
+		if got := index.Nearest(lat, lon, 100); len(got) != len(Stations()) {
+			t.Errorf("wrong number of stations; got %d, expected %d", len(got), len(Stations()))
+		}
+	})
+
+	t.Run("WithinRadius", func(t *testing.T) {
+		tests := []struct {
+			meters   float64
+			expected []string
+		}{
+			{meters: 100, expected: nil},
+			{meters: 600, expected: []string{"EMBR"}},
+			{meters: 1000, expected: []string{"EMBR", "MONT"}},
+		}
+		for _, test := range tests {
+			got := index.WithinRadius(lat, lon, test.meters)
+			if len(got) != len(test.expected) {
+				t.Errorf("%.0f meters; wrong number of stations; got %v, expected %v", test.meters, got, test.expected)
+				continue
+			}
+			for i, stn := range got {
+				if stn.Abbr != test.expected[i] {
+					t.Errorf("%.0f meters; item %d; wrong Abbr; got %q, expected %q", test.meters, i, stn.Abbr, test.expected[i])
+				}
+			}
+		}
+	})
+}
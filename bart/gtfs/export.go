@@ -0,0 +1,276 @@
+package gtfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+)
+
+// AgencyID is the agency_id of BART in an exported Feed.
+const AgencyID = "BART"
+
+// Service IDs in an exported Feed. BART runs a weekday, a Saturday and a
+// Sunday schedule, and holidays run on one of those.
+const (
+	ServiceWeekday  = "WKDY"
+	ServiceSaturday = "SAT"
+	ServiceSunday   = "SUN"
+)
+
+// Options are the inputs to Export. Start and End are the first and last dates
+// of the feed. Only their year, month and day are used. Sched is a schedule
+// number, which you can get from RequestAvailableSchedules, and the zero-value
+// means the current schedule.
+type Options struct {
+	Sched int
+	Start time.Time
+	End   time.Time
+}
+
+// Export builds a Feed of the BART schedule between the dates in opts, with
+// these requests:
+//
+//   - RequestStations for the stops.
+//   - RequestRouteInfo for the routes.
+//   - RequestHolidaySchedules for the holidays, which are exceptions in
+//     calendar_dates.txt.
+//   - RequestRouteSchedulesWithParams for the trips and stop times, once for
+//     each route and each kind of service running between the dates.
+func Export(ctx context.Context, client *bart.Client, opts Options) (*Feed, error) {
+	if opts.Start.IsZero() || opts.End.IsZero() {
+		return nil, errors.New("gtfs: Start and End dates are required")
+	}
+	start := bart.CivilDate(opts.Start)
+	end := bart.CivilDate(opts.End)
+	if end.Before(start) {
+		return nil, fmt.Errorf("gtfs: End %s is before Start %s", end.Format(bart.DateLayout), start.Format(bart.DateLayout))
+	}
+
+	stations, err := client.RequestStationsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := client.RequestRouteInfoContext(ctx, bart.RouteParams{
+		Sched: opts.Sched,
+		Date:  start.Format(bart.DateLayout),
+	})
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := client.RequestHolidaySchedulesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Agencies: []Agency{{
+			ID:       AgencyID,
+			Name:     "Bay Area Rapid Transit",
+			URL:      "https://www.bart.gov",
+			Timezone: bart.Location.String(),
+			Lang:     "en",
+		}},
+	}
+	stopNames := make(map[string]string)
+	for _, stn := range stations.Root.Data.List {
+		abbr := strings.ToUpper(stn.Abbr)
+		stopNames[abbr] = stn.Name
+		feed.Stops = append(feed.Stops, Stop{
+			ID:   abbr,
+			Name: stn.Name,
+			Lat:  float64(stn.Latitude),
+			Lon:  float64(stn.Longitude),
+		})
+	}
+	for _, route := range routes.Root.Data.List {
+		feed.Routes = append(feed.Routes, Route{
+			ID:        strconv.Itoa(route.Number),
+			AgencyID:  AgencyID,
+			ShortName: route.Abbr,
+			LongName:  route.Name,
+			Type:      RouteTypeSubway,
+			Color:     strings.TrimPrefix(route.Hexcolor, "#"),
+		})
+	}
+
+	cal := newServiceCalendar(start, end, holidays)
+	feed.CalendarDates = cal.exceptions
+	for _, service := range []string{ServiceWeekday, ServiceSaturday, ServiceSunday} {
+		date, ok := cal.sampleDates[service]
+		if !ok {
+			continue // The service doesn't run between the dates.
+		}
+		for _, route := range routes.Root.Data.List {
+			res, err := client.RequestRouteSchedulesWithParamsContext(ctx, bart.RouteScheduleParams{
+				Route: route.Number,
+				Date:  date.Format(bart.DateLayout),
+				Sched: opts.Sched,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("gtfs: route %d on %s: %w", route.Number, date.Format(bart.DateLayout), err)
+			}
+			if err = addTrips(feed, service, route, date, res, stopNames); err != nil {
+				return nil, err
+			}
+		}
+		feed.Calendars = append(feed.Calendars, Calendar{
+			ServiceID: service,
+			Weekdays:  serviceWeekdays[service],
+			StartDate: start.Format(DateLayout),
+			EndDate:   end.Format(DateLayout),
+		})
+	}
+
+	// A holiday may move a service off of a date, when that service doesn't
+	// run at all between the dates. There's no need to mention it.
+	running := make(map[string]bool)
+	for _, c := range feed.Calendars {
+		running[c.ServiceID] = true
+	}
+	exceptions := feed.CalendarDates[:0]
+	for _, cd := range feed.CalendarDates {
+		if running[cd.ServiceID] {
+			exceptions = append(exceptions, cd)
+		}
+	}
+	feed.CalendarDates = exceptions
+	return feed, nil
+}
+
+// addTrips adds a trip for each train in a route schedule on date, and its stop
+// times. Stops which the train passes without stopping are left out.
+func addTrips(feed *Feed, service string, route bart.RouteInfo, day time.Time, res bart.RouteSchedulesResponse, stopNames map[string]string) error {
+	date := day.Format(bart.DateLayout)
+	direction := 0
+	if strings.EqualFold(route.Direction, "South") {
+		direction = 1
+	}
+	for _, train := range res.Root.Data.List {
+		trip := Trip{
+			RouteID:     strconv.Itoa(route.Number),
+			ServiceID:   service,
+			ID:          fmt.Sprintf("%s-%d-%d", service, route.Number, train.Index),
+			DirectionID: direction,
+		}
+		var stopTimes []StopTime
+		for _, stop := range train.Stops {
+			if stop.OrigTime == "" {
+				continue
+			}
+			secs, err := serviceSeconds(date, stop.OrigTime)
+			if err != nil {
+				return fmt.Errorf("gtfs: trip %s: %w", trip.ID, err)
+			}
+			stopTimes = append(stopTimes, StopTime{
+				TripID:        trip.ID,
+				ArrivalTime:   formatTime(secs),
+				DepartureTime: formatTime(secs),
+				StopID:        strings.ToUpper(stop.Station),
+				StopSequence:  len(stopTimes) + 1,
+			})
+		}
+		if len(stopTimes) < 2 {
+			continue // It's not a trip if it doesn't go anywhere.
+		}
+		trip.Headsign = stopNames[stopTimes[len(stopTimes)-1].StopID]
+		feed.Trips = append(feed.Trips, trip)
+		feed.StopTimes = append(feed.StopTimes, stopTimes...)
+	}
+	return nil
+}
+
+// serviceSeconds is the time of day on the service date, in seconds since the
+// start of the service day. Times after midnight are on the next day, so
+// they're 24 hours or more.
+func serviceSeconds(date, clock string) (int, error) {
+	t, err := bart.ParseServiceTime(date, clock)
+	if err != nil {
+		return 0, err
+	}
+	day, err := time.ParseInLocation(bart.DateLayout, date, bart.Location)
+	if err != nil {
+		return 0, err
+	}
+	secs := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	if bart.CivilDate(t).After(bart.CivilDate(day)) {
+		secs += 24 * 3600
+	}
+	return secs, nil
+}
+
+// serviceWeekdays are the days of the week each service runs on, when there
+// isn't a holiday.
+var serviceWeekdays = map[string][7]bool{
+	ServiceWeekday:  {time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true},
+	ServiceSaturday: {time.Saturday: true},
+	ServiceSunday:   {time.Sunday: true},
+}
+
+func regularService(day time.Weekday) string {
+	switch day {
+	case time.Saturday:
+		return ServiceSaturday
+	case time.Sunday:
+		return ServiceSunday
+	default:
+		return ServiceWeekday
+	}
+}
+
+// holidayService is the service running on a holiday, given the schedule_type
+// of the holiday, such as "Sunday".
+func holidayService(scheduleType string) string {
+	switch strings.ToLower(strings.TrimSpace(scheduleType)) {
+	case "saturday":
+		return ServiceSaturday
+	case "sunday":
+		return ServiceSunday
+	default:
+		return ServiceWeekday
+	}
+}
+
+// serviceCalendar is which service runs on each date between two dates.
+type serviceCalendar struct {
+	// sampleDates has the first date that each service runs on. The route
+	// schedules for the service are requested for that date.
+	sampleDates map[string]time.Time
+	// exceptions move a service on holidays.
+	exceptions []CalendarDate
+}
+
+func newServiceCalendar(start, end time.Time, res bart.HolidaySchedulesResponse) serviceCalendar {
+	holidays := make(map[string]string)
+	for _, data := range res.Root.Data {
+		for _, h := range data.List {
+			day, err := time.ParseInLocation(bart.DateLayout, h.Date, bart.Location)
+			if err != nil {
+				continue
+			}
+			holidays[day.Format(DateLayout)] = holidayService(h.ScheduleType)
+		}
+	}
+
+	out := serviceCalendar{sampleDates: make(map[string]time.Time)}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(DateLayout)
+		regular := regularService(day.Weekday())
+		service := regular
+		if holiday, ok := holidays[date]; ok && holiday != regular {
+			service = holiday
+			out.exceptions = append(out.exceptions,
+				CalendarDate{ServiceID: regular, Date: date, ExceptionType: ServiceRemoved},
+				CalendarDate{ServiceID: holiday, Date: date, ExceptionType: ServiceAdded},
+			)
+		}
+		if _, ok := out.sampleDates[service]; !ok {
+			out.sampleDates[service] = day
+		}
+	}
+	return out
+}
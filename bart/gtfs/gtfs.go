@@ -0,0 +1,149 @@
+// Package gtfs writes BART schedules in the GTFS static format, the zip of CSV
+// files that most trip planners and map apps import. See
+// https://gtfs.org/schedule/reference/ for the format.
+//
+// Build a Feed from the BART API with Export, then write it with WriteZip:
+//
+//	feed, err := gtfs.Export(ctx, client, gtfs.Options{
+//		Start: time.Date(2026, 11, 1, 0, 0, 0, 0, bart.Location),
+//		End:   time.Date(2026, 11, 30, 0, 0, 0, 0, bart.Location),
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	return feed.WriteZip(w)
+//
+// ReadZip reads a feed back, and Validate checks that the references between
+// its files hold together.
+package gtfs
+
+import "time"
+
+// DateLayout is the format of dates in a feed, for use with time.Format.
+const DateLayout = "20060102"
+
+// A Feed is the contents of a GTFS static feed. Each field is a file in the
+// feed, and each item is a line in that file.
+type Feed struct {
+	Agencies      []Agency
+	Stops         []Stop
+	Routes        []Route
+	Trips         []Trip
+	StopTimes     []StopTime
+	Calendars     []Calendar
+	CalendarDates []CalendarDate
+}
+
+// Agency is a line in agency.txt.
+type Agency struct {
+	ID       string
+	Name     string
+	URL      string
+	Timezone string
+	Lang     string
+	Phone    string
+}
+
+// Stop is a line in stops.txt. The ID is a station abbreviation, such as
+// "12TH".
+type Stop struct {
+	ID   string
+	Name string
+	Lat  float64
+	Lon  float64
+}
+
+// Route type values for Route.Type.
+const (
+	RouteTypeSubway = 1
+	RouteTypeRail   = 2
+)
+
+// Route is a line in routes.txt. The ID is a BART route number.
+type Route struct {
+	ID        string
+	AgencyID  string
+	ShortName string
+	LongName  string
+	Type      int
+	Color     string
+	TextColor string
+}
+
+// Trip is a line in trips.txt. DirectionID is 0 for northbound routes and 1 for
+// southbound routes.
+type Trip struct {
+	RouteID     string
+	ServiceID   string
+	ID          string
+	Headsign    string
+	DirectionID int
+}
+
+// StopTime is a line in stop_times.txt. The ArrivalTime, DepartureTime fields
+// are formatted like "HH:MM:SS", counted from the start of the service day, so
+// trains running after midnight are at "24:00:00" or later.
+type StopTime struct {
+	TripID        string
+	ArrivalTime   string
+	DepartureTime string
+	StopID        string
+	StopSequence  int
+}
+
+// Calendar is a line in calendar.txt. Weekdays is indexed by time.Weekday, and
+// says which days of the week the service runs between StartDate and EndDate.
+// The dates are formatted with DateLayout.
+type Calendar struct {
+	ServiceID string
+	Weekdays  [7]bool
+	StartDate string
+	EndDate   string
+}
+
+// Exception type values for CalendarDate.ExceptionType.
+const (
+	ServiceAdded   = 1
+	ServiceRemoved = 2
+)
+
+// CalendarDate is a line in calendar_dates.txt, which adds or removes a service
+// on one date, such as a holiday. The Date is formatted with DateLayout.
+type CalendarDate struct {
+	ServiceID     string
+	Date          string
+	ExceptionType int
+}
+
+// ServicesOn lists the IDs of the services running on the date of day. A
+// CalendarDate on that date overrides the Calendar of its service.
+func (f *Feed) ServicesOn(day time.Time) (out []string) {
+	date := day.Format(DateLayout)
+	exceptions := make(map[string]int)
+	for _, cd := range f.CalendarDates {
+		if cd.Date == date {
+			exceptions[cd.ServiceID] = cd.ExceptionType
+		}
+	}
+	for _, c := range f.Calendars {
+		runs := c.Weekdays[day.Weekday()] && c.StartDate <= date && date <= c.EndDate
+		switch exceptions[c.ServiceID] {
+		case ServiceAdded:
+			runs = true
+		case ServiceRemoved:
+			runs = false
+		}
+		if runs {
+			out = append(out, c.ServiceID)
+		}
+		delete(exceptions, c.ServiceID)
+	}
+	// Services without a Calendar only run when they're added.
+	for _, cd := range f.CalendarDates {
+		if cd.Date == date && exceptions[cd.ServiceID] == ServiceAdded {
+			out = append(out, cd.ServiceID)
+			delete(exceptions, cd.ServiceID)
+		}
+	}
+	return
+}
@@ -0,0 +1,210 @@
+package gtfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+	"github.com/rafaelespinoza/bart-go/bart/barttest"
+)
+
+func TestExport(t *testing.T) {
+	server := barttest.NewServer()
+	defer server.Close()
+	client := server.Client(nil)
+
+	// Thanksgiving week. Thursday runs the Sunday service and Friday runs the
+	// Saturday service.
+	feed, err := Export(context.Background(), client, Options{
+		Sched: 71,
+		Start: time.Date(2026, 11, 23, 0, 0, 0, 0, bart.Location),
+		End:   time.Date(2026, 11, 29, 0, 0, 0, 0, bart.Location),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = feed.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("requests", func(t *testing.T) {
+		server.AssertRequested(t, "routeinfo", map[string]string{"sched": "71", "date": "11/23/2026"})
+		server.AssertRequestCount(t, "routesched", 3*len(feed.Routes))
+		for _, date := range []string{"11/23/2026", "11/26/2026", "11/27/2026"} {
+			server.AssertRequested(t, "routesched", map[string]string{"route": "1", "date": date, "sched": "71"})
+		}
+	})
+
+	t.Run("calendar", func(t *testing.T) {
+		var services []string
+		for _, c := range feed.Calendars {
+			services = append(services, c.ServiceID)
+			if c.StartDate != "20261123" || c.EndDate != "20261129" {
+				t.Errorf("%s; wrong dates; got %s-%s, expected %s-%s", c.ServiceID, c.StartDate, c.EndDate, "20261123", "20261129")
+			}
+		}
+		if expected := []string{ServiceWeekday, ServiceSaturday, ServiceSunday}; !reflect.DeepEqual(services, expected) {
+			t.Errorf("wrong services; got %v, expected %v", services, expected)
+		}
+
+		expected := []CalendarDate{
+			{ServiceID: ServiceWeekday, Date: "20261126", ExceptionType: ServiceRemoved},
+			{ServiceID: ServiceSunday, Date: "20261126", ExceptionType: ServiceAdded},
+			{ServiceID: ServiceWeekday, Date: "20261127", ExceptionType: ServiceRemoved},
+			{ServiceID: ServiceSaturday, Date: "20261127", ExceptionType: ServiceAdded},
+		}
+		if !reflect.DeepEqual(feed.CalendarDates, expected) {
+			t.Errorf("wrong calendar dates; got %v, expected %v", feed.CalendarDates, expected)
+		}
+	})
+
+	t.Run("trips", func(t *testing.T) {
+		if len(feed.Stops) == 0 || len(feed.Routes) == 0 || len(feed.Trips) == 0 {
+			t.Fatalf("expected stops, routes and trips, got %d, %d, %d", len(feed.Stops), len(feed.Routes), len(feed.Trips))
+		}
+		trip := feed.Trips[0]
+		if trip.ID != "WKDY-1-1" || trip.RouteID != "1" || trip.ServiceID != ServiceWeekday {
+			t.Errorf("unexpected first trip, %+v", trip)
+		}
+		st := feed.StopTimes[0]
+		if st.TripID != trip.ID || st.StopID != "DUBL" || st.DepartureTime != "05:07:00" || st.StopSequence != 1 {
+			t.Errorf("unexpected first stop time, %+v", st)
+		}
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := feed.WriteZip(&buf); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, feed) {
+			t.Error("expected the feed to be the same after writing and reading it")
+		}
+	})
+
+	t.Run("dates", func(t *testing.T) {
+		_, err := Export(context.Background(), client, Options{
+			Start: time.Date(2026, 11, 29, 0, 0, 0, 0, bart.Location),
+			End:   time.Date(2026, 11, 23, 0, 0, 0, 0, bart.Location),
+		})
+		if err == nil {
+			t.Error("expected an error when End is before Start")
+		}
+		if _, err = Export(context.Background(), client, Options{}); err == nil {
+			t.Error("expected an error without dates")
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	feed := &Feed{
+		Agencies: []Agency{{ID: AgencyID, Name: "BART", URL: "https://www.bart.gov", Timezone: "America/Los_Angeles"}},
+		Stops:    []Stop{{ID: "EMBR"}, {ID: "MONT"}, {ID: "MONT"}},
+		Routes:   []Route{{ID: "1", AgencyID: AgencyID, LongName: "Yellow"}},
+		Trips: []Trip{
+			{ID: "a", RouteID: "1", ServiceID: ServiceWeekday},
+			{ID: "b", RouteID: "2", ServiceID: ServiceSunday},
+		},
+		StopTimes: []StopTime{
+			{TripID: "a", StopID: "EMBR", ArrivalTime: "25:01:00", DepartureTime: "25:01:00", StopSequence: 1},
+			{TripID: "a", StopID: "NOPE", ArrivalTime: "25:00:00", DepartureTime: "25:00:00", StopSequence: 2},
+			{TripID: "c", StopID: "EMBR", ArrivalTime: "08:00:00", DepartureTime: "08:00:00", StopSequence: 1},
+		},
+		Calendars: []Calendar{{ServiceID: ServiceWeekday, StartDate: "20261123", EndDate: "20261129"}},
+	}
+
+	err := feed.Validate()
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) {
+		t.Fatalf("expected %T, got %T (%v)", valErr, err, err)
+	}
+	expected := []string{
+		`duplicate stop_id "MONT"`,
+		`trip "b" refers to unknown route "2"`,
+		`trip "b" refers to unknown service "SUN"`,
+		`trip "a" refers to unknown stop "NOPE"`,
+		`trip "a" goes back in time at stop 2`,
+		`unknown trip "c"`,
+		`trip "b" has fewer than 2 stops`,
+	}
+	for _, msg := range expected {
+		var found bool
+		for _, problem := range valErr.Problems {
+			found = found || strings.Contains(problem, msg)
+		}
+		if !found {
+			t.Errorf("expected a problem like %q, got %q", msg, valErr.Problems)
+		}
+	}
+	if len(valErr.Problems) != len(expected) {
+		t.Errorf("wrong number of problems; got %d, expected %d", len(valErr.Problems), len(expected))
+	}
+}
+
+func TestServicesOn(t *testing.T) {
+	feed := &Feed{
+		Calendars: []Calendar{
+			{ServiceID: ServiceWeekday, Weekdays: serviceWeekdays[ServiceWeekday], StartDate: "20261101", EndDate: "20261130"},
+			{ServiceID: ServiceSaturday, Weekdays: serviceWeekdays[ServiceSaturday], StartDate: "20261101", EndDate: "20261130"},
+		},
+		CalendarDates: []CalendarDate{
+			// Thanksgiving runs the Sunday service, which has no calendar.
+			{ServiceID: ServiceWeekday, Date: "20261126", ExceptionType: ServiceRemoved},
+			{ServiceID: ServiceSunday, Date: "20261126", ExceptionType: ServiceAdded},
+			// The day after runs the Saturday service.
+			{ServiceID: ServiceWeekday, Date: "20261127", ExceptionType: ServiceRemoved},
+			{ServiceID: ServiceSaturday, Date: "20261127", ExceptionType: ServiceAdded},
+		},
+	}
+	tests := []struct {
+		day      int
+		expected []string
+	}{
+		{day: 25, expected: []string{ServiceWeekday}},
+		{day: 26, expected: []string{ServiceSunday}},
+		{day: 27, expected: []string{ServiceSaturday}},
+		{day: 28, expected: []string{ServiceSaturday}},
+		{day: 29, expected: nil},
+	}
+	for _, test := range tests {
+		day := time.Date(2026, 11, test.day, 0, 0, 0, 0, bart.Location)
+		if got := feed.ServicesOn(day); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s; got %q, expected %q", day.Format(DateLayout), got, test.expected)
+		}
+	}
+
+	day := time.Date(2026, 12, 1, 0, 0, 0, 0, bart.Location)
+	if got := feed.ServicesOn(day); got != nil {
+		t.Errorf("%s; expected no services after the calendars end, got %q", day.Format(DateLayout), got)
+	}
+}
+
+func TestServiceSeconds(t *testing.T) {
+	tests := []struct {
+		clock    string
+		expected string
+	}{
+		{clock: "5:07 AM", expected: "05:07:00"},
+		{clock: "11:59 PM", expected: "23:59:00"},
+		{clock: "12:11 AM", expected: "24:11:00"},
+		{clock: "1:30 AM", expected: "25:30:00"},
+	}
+	for _, test := range tests {
+		secs, err := serviceSeconds("11/23/2026", test.clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := formatTime(secs); got != test.expected {
+			t.Errorf("%s; got %q, expected %q", test.clock, got, test.expected)
+		}
+	}
+}
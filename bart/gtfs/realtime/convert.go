@@ -0,0 +1,249 @@
+package realtime
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+	"github.com/rafaelespinoza/bart-go/bart/gtfs"
+)
+
+// maxGap is the most minutes a train is expected to take between two stations
+// with estimates on a route. Some stations may be missing from a response, so
+// it's longer than the time between any two neighbors.
+const maxGap = 15
+
+// maxSlip is the most that a train's predicted departure, less its delay, may
+// differ from the stop time of the trip it's matched to. Estimates are in whole
+// minutes, so they're never exact.
+const maxSlip = 2 * time.Minute
+
+// TripUpdates makes a feed of predicted departures from the estimates at every
+// station, such as from RequestETD with "ALL" for orig, for the trips in a
+// static feed, such as from gtfs.Export. Each departure is put on the route of
+// the same color, which goes through its station and then its destination.
+//
+// The BART API doesn't identify trains, so the departures on a route are
+// chained into trains by their minutes: a train leaving an earlier station in
+// m minutes leaves a later one in more than m minutes. It's a good guess, but a
+// guess. Then each train is matched to the trip on its route, running on the
+// service of the day, which is scheduled to leave its first station closest to
+// when it's predicted to, less its delay. The trip ID is the one in the static
+// feed, so it stays the same from one feed to the next, and delays are from its
+// stop times. Trains without a trip are left out, so the static feed should
+// cover the current date.
+//
+// Times are relative to now, which should be when the estimates were requested.
+func TripUpdates(res bart.EstimatesResponse, routes bart.RoutesInfoResponse, static *gtfs.Feed, now time.Time) FeedMessage {
+	type routeDepartures struct {
+		route bart.RouteInfo
+		stops map[string]bart.Departures
+	}
+	byRoute := make([]routeDepartures, len(routes.Root.Data.List))
+	for i, route := range routes.Root.Data.List {
+		byRoute[i] = routeDepartures{route: route, stops: make(map[string]bart.Departures)}
+	}
+	for _, stn := range res.Root.Data {
+		for _, dep := range stn.Departures() {
+			i := findRoute(routes.Root.Data.List, dep)
+			if i < 0 {
+				continue
+			}
+			stop := strings.ToUpper(dep.Station)
+			byRoute[i].stops[stop] = append(byRoute[i].stops[stop], dep)
+		}
+	}
+
+	sched := newSchedule(static)
+	out := FeedMessage{Header: FeedHeader{Version: Version, Timestamp: uint64(now.Unix())}}
+	seen := make(map[string]bool)
+	for _, rd := range byRoute {
+		for _, tr := range chainTrains(rd.route, rd.stops) {
+			update := tr.tripUpdate(sched, strconv.Itoa(rd.route.Number), now)
+			if update == nil || seen[update.Trip.TripID] {
+				continue
+			}
+			seen[update.Trip.TripID] = true
+			out.Entities = append(out.Entities, FeedEntity{ID: update.Trip.TripID, TripUpdate: update})
+		}
+	}
+	return out
+}
+
+// findRoute is the index of the route of dep, or -1 if there isn't one. If many
+// routes go from the station to the destination, it prefers one ending at the
+// destination, and then one going in the same direction.
+func findRoute(routes []bart.RouteInfo, dep bart.Departure) int {
+	stop := strings.ToUpper(dep.Station)
+	dest := strings.ToUpper(dep.Abbreviation)
+	found, score := -1, -1
+	for i, route := range routes {
+		if dep.Color != "" && !strings.EqualFold(route.Color, dep.Color) {
+			continue
+		}
+		if !servesInOrder(route.Config.Stations, stop, dest) {
+			continue
+		}
+		var s int
+		if strings.EqualFold(route.Destination, dest) {
+			s += 2
+		}
+		if strings.EqualFold(route.Direction, dep.Direction) {
+			s++
+		}
+		if s > score {
+			found, score = i, s
+		}
+	}
+	return found
+}
+
+// servesInOrder reports whether stop comes before dest in stations.
+func servesInOrder(stations []string, stop, dest string) bool {
+	seen := false
+	for _, stn := range stations {
+		switch strings.ToUpper(stn) {
+		case stop:
+			seen = true
+		case dest:
+			return seen
+		}
+	}
+	return false
+}
+
+// train is a chain of departures of one train at successive stations. stop is
+// the index, on the route, of the station of the last departure.
+type train struct {
+	departures bart.Departures
+	stop       int
+}
+
+func (t *train) last() bart.Departure { return t.departures[len(t.departures)-1] }
+
+// chainTrains puts departures on a route into trains. At each station, in order,
+// the soonest departures are matched first, each to a train which left before
+// it at an earlier station. A train seen at a closer station is preferred, and
+// then the one which left the latest.
+func chainTrains(route bart.RouteInfo, stops map[string]bart.Departures) (out []*train) {
+	for i, stn := range route.Config.Stations {
+		deps := stops[strings.ToUpper(stn)].SortByMinutes()
+		matched := make(map[*train]bool)
+		for _, dep := range deps {
+			var best *train
+			for _, tr := range out {
+				prev := tr.last().Minutes
+				if matched[tr] || prev >= dep.Minutes || dep.Minutes-prev > maxGap {
+					continue
+				}
+				if best == nil || tr.stop > best.stop || (tr.stop == best.stop && prev > best.last().Minutes) {
+					best = tr
+				}
+			}
+			if best == nil {
+				best = &train{}
+				out = append(out, best)
+			}
+			best.departures = append(best.departures, dep)
+			best.stop = i
+			matched[best] = true
+		}
+	}
+	return
+}
+
+func (t *train) tripUpdate(sched *schedule, routeID string, now time.Time) *TripUpdate {
+	first := t.departures[0]
+	at := now.Add(time.Duration(first.Minutes)*time.Minute - time.Duration(first.Delay)*time.Second)
+	trip, date, ok := sched.match(routeID, strings.ToUpper(first.Station), at)
+	if !ok {
+		return nil
+	}
+	update := &TripUpdate{
+		Trip: TripDescriptor{
+			TripID:      trip.ID,
+			RouteID:     trip.RouteID,
+			DirectionID: uint32(trip.DirectionID),
+			StartDate:   date.Format(gtfs.DateLayout),
+		},
+		Timestamp: uint64(now.Unix()),
+	}
+	for _, dep := range t.departures {
+		stop := strings.ToUpper(dep.Station)
+		secs, ok := sched.stopTimes[trip.ID][stop]
+		if !ok {
+			continue // The trip doesn't stop there.
+		}
+		stu := StopTimeUpdate{StopID: stop}
+		if dep.CancelFlag {
+			stu.ScheduleRelationship = StopSkipped
+		} else {
+			predicted := now.Add(time.Duration(dep.Minutes) * time.Minute)
+			scheduled := serviceTime(date, secs)
+			stu.Departure = &StopTimeEvent{
+				Delay: int32(clampInt32(int64(predicted.Sub(scheduled) / time.Second))),
+				Time:  predicted.Unix(),
+			}
+		}
+		update.StopTimeUpdates = append(update.StopTimeUpdates, stu)
+	}
+	return update
+}
+
+// Alerts makes a feed of service alerts from advisories, such as from
+// RequestBSA. The placeholder advisory, which BART sends when there aren't any,
+// is left out. Advisories for the whole system inform the agency, and the others
+// inform their station.
+func Alerts(res bart.AdvisoriesBSAResponse, now time.Time) FeedMessage {
+	out := FeedMessage{Header: FeedHeader{Version: Version, Timestamp: uint64(now.Unix())}}
+	for _, adv := range res.Root.Data {
+		if adv.Type == "" {
+			continue
+		}
+		alert := &Alert{
+			Cause:           CauseUnknown,
+			Effect:          alertEffect(adv.Type),
+			HeaderText:      strings.TrimSpace(adv.SMSText.Value),
+			DescriptionText: strings.TrimSpace(adv.Description.Value),
+		}
+		if alert.HeaderText == "" {
+			alert.HeaderText = alert.DescriptionText
+		}
+
+		var period TimeRange
+		if posted, err := adv.PostedTime(); err == nil && !posted.IsZero() {
+			period.Start = uint64(posted.Unix())
+		}
+		if expires, err := adv.ExpiresTime(); err == nil && !expires.IsZero() {
+			period.End = uint64(expires.Unix())
+		}
+		if period != (TimeRange{}) {
+			alert.ActivePeriods = []TimeRange{period}
+		}
+
+		if stn := strings.ToUpper(strings.TrimSpace(adv.Station)); stn == "" || stn == gtfs.AgencyID {
+			alert.InformedEntities = []EntitySelector{{AgencyID: gtfs.AgencyID}}
+		} else {
+			alert.InformedEntities = []EntitySelector{{StopID: stn}}
+		}
+
+		id := adv.ID
+		if id == "" {
+			id = adv.Fingerprint()
+		}
+		out.Entities = append(out.Entities, FeedEntity{ID: id, Alert: alert})
+	}
+	return out
+}
+
+func alertEffect(typ bart.AdvisoryType) int {
+	switch typ {
+	case bart.AdvisoryDelay:
+		return EffectSignificantDelays
+	case bart.AdvisoryElevator:
+		return EffectAccessibilityIssue
+	default:
+		return EffectOther
+	}
+}
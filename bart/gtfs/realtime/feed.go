@@ -0,0 +1,236 @@
+// Package realtime publishes live BART data as GTFS-realtime feeds, the
+// protocol buffer companion to a static feed made by package gtfs. TripUpdates
+// puts the estimated departures in an EstimatesResponse onto the trips of a
+// static feed, and Alerts makes a feed of service alerts from an
+// AdvisoriesBSAResponse. A Handler serves both over HTTP. See
+// https://gtfs.org/realtime/reference/ for the format.
+//
+// The stop, route and trip IDs are the ones in the static feed, so consumers
+// can join the two.
+package realtime
+
+// Version is the GTFS-realtime version of the feeds in this package.
+const Version = "2.0"
+
+// A FeedMessage is a GTFS-realtime feed. Use Marshal to encode it.
+type FeedMessage struct {
+	Header   FeedHeader
+	Entities []FeedEntity
+}
+
+// FeedHeader is metadata about a feed. Timestamp is when the feed's content was
+// made, in seconds since the Unix epoch. Every feed in this package is a full
+// dataset, rather than an update to a previous one.
+type FeedHeader struct {
+	Version   string
+	Timestamp uint64
+}
+
+// FeedEntity is one update in a feed. It has either a TripUpdate or an Alert.
+type FeedEntity struct {
+	ID         string
+	TripUpdate *TripUpdate
+	Alert      *Alert
+}
+
+// TripUpdate is the predicted times for one train at the stations ahead of it.
+type TripUpdate struct {
+	Trip            TripDescriptor
+	StopTimeUpdates []StopTimeUpdate
+	Timestamp       uint64
+}
+
+// Schedule relationships of a trip, for TripDescriptor.ScheduleRelationship.
+const (
+	TripScheduled   = 0
+	TripAdded       = 1
+	TripUnscheduled = 2
+	TripCanceled    = 3
+)
+
+// TripDescriptor identifies the trip of a TripUpdate. DirectionID is 0 for
+// northbound routes and 1 for southbound routes. StartDate is the service date
+// of the trip, formatted with gtfs.DateLayout.
+type TripDescriptor struct {
+	TripID               string
+	RouteID              string
+	DirectionID          uint32
+	StartDate            string
+	ScheduleRelationship int
+}
+
+// Schedule relationships of a stop, for StopTimeUpdate.ScheduleRelationship.
+const (
+	StopScheduled = 0
+	StopSkipped   = 1
+	StopNoData    = 2
+)
+
+// StopTimeUpdate is the predicted departure of a train from one stop.
+type StopTimeUpdate struct {
+	StopID               string
+	Departure            *StopTimeEvent
+	ScheduleRelationship int
+}
+
+// StopTimeEvent is a predicted time, in seconds since the Unix epoch, and how
+// late that is compared to the static feed, in seconds.
+type StopTimeEvent struct {
+	Delay int32
+	Time  int64
+}
+
+// Causes of an alert, for Alert.Cause.
+const (
+	CauseUnknown          = 1
+	CauseOther            = 2
+	CauseTechnicalProblem = 3
+	CauseMaintenance      = 9
+)
+
+// Effects of an alert, for Alert.Effect.
+const (
+	EffectNoService          = 1
+	EffectReducedService     = 2
+	EffectSignificantDelays  = 3
+	EffectOther              = 7
+	EffectUnknown            = 8
+	EffectAccessibilityIssue = 11
+)
+
+// Alert is a service alert. HeaderText and DescriptionText are in English.
+type Alert struct {
+	ActivePeriods    []TimeRange
+	InformedEntities []EntitySelector
+	Cause            int
+	Effect           int
+	HeaderText       string
+	DescriptionText  string
+}
+
+// TimeRange is when an alert is in effect, in seconds since the Unix epoch. A
+// zero value for Start or End means it's open-ended.
+type TimeRange struct {
+	Start uint64
+	End   uint64
+}
+
+// EntitySelector is the agency, route or stop affected by an alert.
+type EntitySelector struct {
+	AgencyID string
+	RouteID  string
+	StopID   string
+}
+
+// Marshal encodes the feed in the protocol buffers wire format, which is how
+// GTFS-realtime feeds are served.
+func (m *FeedMessage) Marshal() []byte {
+	var e encoder
+	e.message(1, func(e *encoder) {
+		version := m.Header.Version
+		if version == "" {
+			version = Version
+		}
+		e.string(1, version)
+		e.uint(2, 0) // FULL_DATASET
+		if m.Header.Timestamp > 0 {
+			e.uint(3, m.Header.Timestamp)
+		}
+	})
+	for _, entity := range m.Entities {
+		e.message(2, entity.encode)
+	}
+	return e.buf
+}
+
+func (f FeedEntity) encode(e *encoder) {
+	e.string(1, f.ID)
+	if f.TripUpdate != nil {
+		e.message(3, f.TripUpdate.encode)
+	}
+	if f.Alert != nil {
+		e.message(5, f.Alert.encode)
+	}
+}
+
+func (t *TripUpdate) encode(e *encoder) {
+	e.message(1, t.Trip.encode)
+	for _, stu := range t.StopTimeUpdates {
+		e.message(2, stu.encode)
+	}
+	if t.Timestamp > 0 {
+		e.uint(4, t.Timestamp)
+	}
+}
+
+func (t TripDescriptor) encode(e *encoder) {
+	e.string(1, t.TripID)
+	e.string(3, t.StartDate)
+	if t.ScheduleRelationship != TripScheduled {
+		e.uint(4, uint64(t.ScheduleRelationship))
+	}
+	e.string(5, t.RouteID)
+	if t.RouteID != "" {
+		e.uint(6, uint64(t.DirectionID))
+	}
+}
+
+func (s StopTimeUpdate) encode(e *encoder) {
+	if s.Departure != nil {
+		e.message(3, s.Departure.encode)
+	}
+	e.string(4, s.StopID)
+	if s.ScheduleRelationship != StopScheduled {
+		e.uint(5, uint64(s.ScheduleRelationship))
+	}
+}
+
+func (s *StopTimeEvent) encode(e *encoder) {
+	e.int(1, int64(s.Delay))
+	e.int(2, s.Time)
+}
+
+func (a *Alert) encode(e *encoder) {
+	for _, period := range a.ActivePeriods {
+		e.message(1, period.encode)
+	}
+	for _, entity := range a.InformedEntities {
+		e.message(5, entity.encode)
+	}
+	if a.Cause != 0 {
+		e.uint(6, uint64(a.Cause))
+	}
+	if a.Effect != 0 {
+		e.uint(7, uint64(a.Effect))
+	}
+	if a.HeaderText != "" {
+		e.message(10, translatedString(a.HeaderText))
+	}
+	if a.DescriptionText != "" {
+		e.message(11, translatedString(a.DescriptionText))
+	}
+}
+
+func (t TimeRange) encode(e *encoder) {
+	if t.Start > 0 {
+		e.uint(1, t.Start)
+	}
+	if t.End > 0 {
+		e.uint(2, t.End)
+	}
+}
+
+func (s EntitySelector) encode(e *encoder) {
+	e.string(1, s.AgencyID)
+	e.string(2, s.RouteID)
+	e.string(5, s.StopID)
+}
+
+func translatedString(text string) func(e *encoder) {
+	return func(e *encoder) {
+		e.message(1, func(e *encoder) {
+			e.string(1, text)
+			e.string(2, "en")
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package realtime
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+	"github.com/rafaelespinoza/bart-go/bart/gtfs"
+)
+
+// ContentType is the media type of a GTFS-realtime feed.
+const ContentType = "application/x-protobuf"
+
+// Handler serves GTFS-realtime feeds made from requests to the BART API:
+//
+//   - /trip-updates is TripUpdates, from RequestETD for every station and
+//     RequestRoutesInfo, for the trips in the static feed.
+//   - /alerts is Alerts, from RequestBSA.
+//
+// Every request to the Handler makes requests to the BART API, so give the
+// client a Cache in its bart.Config, if many consumers share it. Use
+// http.StripPrefix to mount it under another path.
+type Handler struct {
+	client *bart.Client
+	static *gtfs.Feed
+	now    func() time.Time
+}
+
+// NewHandler makes a Handler which makes its requests with client. The trip
+// updates are for the trips in static, such as from gtfs.Export, so it should
+// cover the dates that the Handler is serving.
+func NewHandler(client *bart.Client, static *gtfs.Feed) *Handler {
+	return &Handler{client: client, static: static, now: time.Now}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var feed func(ctx context.Context) (FeedMessage, error)
+	switch r.URL.Path {
+	case "/trip-updates":
+		feed = h.tripUpdates
+	case "/alerts":
+		feed = h.alerts
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	msg, err := feed(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Write(msg.Marshal())
+}
+
+func (h *Handler) tripUpdates(ctx context.Context) (out FeedMessage, err error) {
+	etds, err := h.client.RequestETDContext(ctx, "ALL", "", "")
+	if err != nil {
+		return
+	}
+	routes, err := h.client.RequestRoutesInfoContext(ctx, "")
+	if err != nil {
+		return
+	}
+	out = TripUpdates(etds, routes, h.static, h.now())
+	return
+}
+
+func (h *Handler) alerts(ctx context.Context) (out FeedMessage, err error) {
+	res, err := h.client.RequestBSAContext(ctx)
+	if err != nil {
+		return
+	}
+	out = Alerts(res, h.now())
+	return
+}
@@ -0,0 +1,72 @@
+package realtime
+
+import "math"
+
+// encoder writes the protocol buffers wire format. There are only a few
+// messages in a GTFS-realtime feed, so they're encoded by hand instead of with
+// generated code. See https://protobuf.dev/programming-guides/encoding/.
+type encoder struct {
+	buf []byte
+}
+
+// Wire types.
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (e *encoder) tag(field, wireType int) {
+	e.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (e *encoder) varint(val uint64) {
+	for val >= 0x80 {
+		e.buf = append(e.buf, byte(val)|0x80)
+		val >>= 7
+	}
+	e.buf = append(e.buf, byte(val))
+}
+
+// uint writes an unsigned integer field, such as a uint32 or uint64.
+func (e *encoder) uint(field int, val uint64) {
+	e.tag(field, wireVarint)
+	e.varint(val)
+}
+
+// int writes a signed integer field, such as an int32 or int64. Negative
+// values are sign-extended to 64 bits, as the format requires, so they always
+// take 10 bytes.
+func (e *encoder) int(field int, val int64) {
+	e.tag(field, wireVarint)
+	e.varint(uint64(val))
+}
+
+// string writes a string field, unless it's empty. None of the strings in a
+// feed mean anything when they're empty, so there's no need for them.
+func (e *encoder) string(field int, val string) {
+	if val == "" {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.varint(uint64(len(val)))
+	e.buf = append(e.buf, val...)
+}
+
+// message writes an embedded message field, with the fields written by fn.
+func (e *encoder) message(field int, fn func(e *encoder)) {
+	var sub encoder
+	fn(&sub)
+	e.tag(field, wireBytes)
+	e.varint(uint64(len(sub.buf)))
+	e.buf = append(e.buf, sub.buf...)
+}
+
+// clampInt32 keeps val in range of an int32 field.
+func clampInt32(val int64) int64 {
+	if val > math.MaxInt32 {
+		return math.MaxInt32
+	} else if val < math.MinInt32 {
+		return math.MinInt32
+	}
+	return val
+}
@@ -0,0 +1,498 @@
+package realtime
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+	"github.com/rafaelespinoza/bart-go/bart/barttest"
+	"github.com/rafaelespinoza/bart-go/bart/gtfs"
+)
+
+// now is when the fixtures in barttest were requested.
+var now = time.Date(2026, 10, 17, 8, 15, 0, 0, bart.Location)
+
+// static is a static feed with the trips of some trains in the barttest
+// fixtures, which are on a Saturday.
+func static() *gtfs.Feed {
+	feed := &gtfs.Feed{
+		Calendars: []gtfs.Calendar{
+			{ServiceID: gtfs.ServiceWeekday, Weekdays: [7]bool{time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true}, StartDate: "20261001", EndDate: "20261231"},
+			{ServiceID: gtfs.ServiceSaturday, Weekdays: [7]bool{time.Saturday: true}, StartDate: "20261001", EndDate: "20261231"},
+		},
+	}
+	addTrip := func(service, route string, direction int, index int, stops []string, times []string) {
+		id := fmt.Sprintf("%s-%s-%d", service, route, index)
+		feed.Trips = append(feed.Trips, gtfs.Trip{RouteID: route, ServiceID: service, ID: id, DirectionID: direction})
+		for i, stop := range stops {
+			feed.StopTimes = append(feed.StopTimes, gtfs.StopTime{TripID: id, ArrivalTime: times[i], DepartureTime: times[i], StopID: stop, StopSequence: i + 1})
+		}
+	}
+	addTrip(gtfs.ServiceSaturday, "7", 1, 1, []string{"MCAR", "12TH", "EMBR"}, []string{"08:15:00", "08:18:00", "08:24:00"})
+	addTrip(gtfs.ServiceSaturday, "7", 1, 2, []string{"MCAR", "12TH", "EMBR"}, []string{"08:30:00", "08:33:00", "08:39:00"})
+	addTrip(gtfs.ServiceSaturday, "4", 1, 1, []string{"MCAR", "12TH"}, []string{"08:15:00", "08:19:00"})
+	addTrip(gtfs.ServiceSaturday, "2", 0, 1, []string{"EMBR", "12TH", "MCAR"}, []string{"08:16:00", "08:29:00", "08:34:00"})
+	// The same times on another service.
+	addTrip(gtfs.ServiceWeekday, "7", 1, 1, []string{"MCAR", "12TH", "EMBR"}, []string{"08:15:00", "08:18:00", "08:24:00"})
+	return feed
+}
+
+func TestTripUpdates(t *testing.T) {
+	server := barttest.NewServer()
+	defer server.Close()
+	client := server.Client(nil)
+
+	etds, err := client.RequestETD("ALL", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes, err := client.RequestRoutesInfo("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := TripUpdates(etds, routes, static(), now)
+
+	trips := make(map[string]*TripUpdate)
+	var ids []string
+	for _, entity := range feed.Entities {
+		if entity.ID != entity.TripUpdate.Trip.TripID {
+			t.Errorf("wrong entity ID; got %q, expected %q", entity.ID, entity.TripUpdate.Trip.TripID)
+		}
+		if trips[entity.ID] != nil {
+			t.Errorf("duplicate entity ID %q", entity.ID)
+		}
+		trips[entity.ID] = entity.TripUpdate
+		ids = append(ids, entity.ID)
+	}
+	// Trains without a trip in the static feed are left out.
+	sort.Strings(ids)
+	if expected := []string{"SAT-2-1", "SAT-4-1", "SAT-7-1", "SAT-7-2"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("wrong trips; got %q, expected %q", ids, expected)
+	}
+
+	tests := []struct {
+		id        string
+		routeID   string
+		direction uint32
+		stops     []string
+		minutes   []int
+		delays    []int32
+	}{
+		// Red line to Millbrae: Leaving MCAR, 3 minutes from 12TH, 9 from EMBR.
+		{id: "SAT-7-1", routeID: "7", direction: 1, stops: []string{"MCAR", "12TH", "EMBR"}, minutes: []int{0, 3, 9}, delays: []int32{0, 0, 0}},
+		{id: "SAT-7-2", routeID: "7", direction: 1, stops: []string{"MCAR", "12TH", "EMBR"}, minutes: []int{15, 18, 24}, delays: []int32{0, 0, 0}},
+		// Delayed orange line train to Berryessa, 2 minutes behind its stop
+		// times.
+		{id: "SAT-4-1", routeID: "4", direction: 1, stops: []string{"MCAR", "12TH"}, minutes: []int{2, 6}, delays: []int32{120, 120}},
+		// Yellow line to Antioch, going north through 12TH before MCAR.
+		{id: "SAT-2-1", routeID: "2", direction: 0, stops: []string{"EMBR", "12TH", "MCAR"}, minutes: []int{1, 14, 19}, delays: []int32{0, 0, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			update, ok := trips[test.id]
+			if !ok {
+				t.Fatalf("missing trip; got %q", ids)
+			}
+			if update.Trip.RouteID != test.routeID {
+				t.Errorf("wrong route; got %q, expected %q", update.Trip.RouteID, test.routeID)
+			}
+			if update.Trip.DirectionID != test.direction {
+				t.Errorf("wrong direction; got %d, expected %d", update.Trip.DirectionID, test.direction)
+			}
+			if update.Trip.StartDate != "20261017" {
+				t.Errorf("wrong start date; got %q, expected %q", update.Trip.StartDate, "20261017")
+			}
+			if update.Trip.ScheduleRelationship != TripScheduled {
+				t.Errorf("wrong schedule relationship; got %d, expected %d", update.Trip.ScheduleRelationship, TripScheduled)
+			}
+			var stops []string
+			var minutes []int
+			var delays []int32
+			for _, stu := range update.StopTimeUpdates {
+				stops = append(stops, stu.StopID)
+				minutes = append(minutes, int(time.Unix(stu.Departure.Time, 0).Sub(now)/time.Minute))
+				delays = append(delays, stu.Departure.Delay)
+			}
+			if !reflect.DeepEqual(stops, test.stops) {
+				t.Errorf("wrong stops; got %q, expected %q", stops, test.stops)
+			}
+			if !reflect.DeepEqual(minutes, test.minutes) {
+				t.Errorf("wrong minutes; got %v, expected %v", minutes, test.minutes)
+			}
+			if !reflect.DeepEqual(delays, test.delays) {
+				t.Errorf("wrong delays; got %v, expected %v", delays, test.delays)
+			}
+		})
+	}
+
+	t.Run("next poll", func(t *testing.T) {
+		// Two minutes later, some trains have left their first station, but
+		// they're still on the same trips.
+		later := etds
+		later.Root.Data = nil
+		for _, stn := range etds.Root.Data {
+			next := stn
+			next.Etds = nil
+			for _, etd := range stn.Etds {
+				nextETD := etd
+				nextETD.Estimates = nil
+				for _, est := range etd.Estimates {
+					if est.Minutes -= 2; est.Minutes >= 0 {
+						nextETD.Estimates = append(nextETD.Estimates, est)
+					}
+				}
+				next.Etds = append(next.Etds, nextETD)
+			}
+			later.Root.Data = append(later.Root.Data, next)
+		}
+
+		var got []string
+		for _, entity := range TripUpdates(later, routes, static(), now.Add(2*time.Minute)).Entities {
+			got = append(got, entity.ID)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, ids) {
+			t.Errorf("wrong trips; got %q, expected %q", got, ids)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		var res bart.EstimatesResponse
+		res.Root.Data = []bart.StationETDs{{
+			Abbr: "MCAR",
+			Etds: []bart.ETD{{
+				Abbreviation: "MLBR",
+				Estimates:    []bart.Estimate{{Minutes: 15, Color: "RED", Direction: "South", CancelFlag: true}},
+			}},
+		}}
+		feed := TripUpdates(res, routes, static(), now)
+		if len(feed.Entities) != 1 {
+			t.Fatalf("wrong number of entities; got %d, expected %d", len(feed.Entities), 1)
+		}
+		stu := feed.Entities[0].TripUpdate.StopTimeUpdates[0]
+		if stu.ScheduleRelationship != StopSkipped || stu.Departure != nil {
+			t.Errorf("expected a skipped stop without a departure, got %+v", stu)
+		}
+	})
+
+	t.Run("after midnight", func(t *testing.T) {
+		// A train leaving at 12:30 AM on Sunday is on Saturday's service.
+		feed := static()
+		feed.Trips = append(feed.Trips, gtfs.Trip{RouteID: "7", ServiceID: gtfs.ServiceSaturday, ID: "SAT-7-99", DirectionID: 1})
+		feed.StopTimes = append(feed.StopTimes, gtfs.StopTime{TripID: "SAT-7-99", ArrivalTime: "24:30:00", DepartureTime: "24:30:00", StopID: "MCAR", StopSequence: 1})
+
+		at := time.Date(2026, 10, 18, 0, 31, 0, 0, bart.Location)
+		trip, date, ok := newSchedule(feed).match("7", "MCAR", at)
+		if !ok || trip.ID != "SAT-7-99" {
+			t.Fatalf("wrong trip; got %q, expected %q", trip.ID, "SAT-7-99")
+		}
+		if expected := time.Date(2026, 10, 17, 0, 0, 0, 0, bart.Location); !date.Equal(expected) {
+			t.Errorf("wrong service date; got %v, expected %v", date, expected)
+		}
+		if _, _, ok = newSchedule(feed).match("7", "MCAR", at.Add(5*time.Minute)); ok {
+			t.Error("expected no trip more than maxSlip from the stop time")
+		}
+	})
+
+	t.Run("no static feed", func(t *testing.T) {
+		if feed := TripUpdates(etds, routes, nil, now); len(feed.Entities) != 0 {
+			t.Errorf("expected no entities, got %d", len(feed.Entities))
+		}
+	})
+}
+
+func TestAlerts(t *testing.T) {
+	var res bart.AdvisoriesBSAResponse
+	res.Root.Data = []bart.Advisory{
+		{
+			ID:          "229331",
+			Station:     "BART",
+			Type:        bart.AdvisoryDelay,
+			Description: bart.CDATASection{Value: "There is a 10-minute delay at West Oakland."},
+			SMSText:     bart.CDATASection{Value: "10-min delay at WOAK."},
+			Posted:      "Sat Oct 17 2026 08:02 AM PDT",
+			Expires:     "Sat Oct 17 2026 11:59 PM PDT",
+		},
+		{
+			Station:     "MONT",
+			Type:        bart.AdvisoryElevator,
+			Description: bart.CDATASection{Value: "The street elevator at Montgomery is out of service."},
+		},
+		{Description: bart.CDATASection{Value: "No delays reported."}},
+	}
+	feed := Alerts(res, now)
+	if len(feed.Entities) != 2 {
+		t.Fatalf("wrong number of entities; got %d, expected %d", len(feed.Entities), 2)
+	}
+
+	delay := feed.Entities[0]
+	expected := &Alert{
+		ActivePeriods: []TimeRange{{
+			Start: uint64(time.Date(2026, 10, 17, 8, 2, 0, 0, bart.Location).Unix()),
+			End:   uint64(time.Date(2026, 10, 17, 23, 59, 0, 0, bart.Location).Unix()),
+		}},
+		InformedEntities: []EntitySelector{{AgencyID: gtfs.AgencyID}},
+		Cause:            CauseUnknown,
+		Effect:           EffectSignificantDelays,
+		HeaderText:       "10-min delay at WOAK.",
+		DescriptionText:  "There is a 10-minute delay at West Oakland.",
+	}
+	if delay.ID != "229331" {
+		t.Errorf("wrong ID; got %q, expected %q", delay.ID, "229331")
+	}
+	if !reflect.DeepEqual(delay.Alert, expected) {
+		t.Errorf("wrong alert;\ngot      %+v\nexpected %+v", delay.Alert, expected)
+	}
+
+	elevator := feed.Entities[1]
+	if elevator.ID != res.Root.Data[1].Fingerprint() {
+		t.Errorf("wrong ID; got %q, expected %q", elevator.ID, res.Root.Data[1].Fingerprint())
+	}
+	if elevator.Alert.Effect != EffectAccessibilityIssue {
+		t.Errorf("wrong effect; got %d, expected %d", elevator.Alert.Effect, EffectAccessibilityIssue)
+	}
+	if got := elevator.Alert.InformedEntities; !reflect.DeepEqual(got, []EntitySelector{{StopID: "MONT"}}) {
+		t.Errorf("wrong informed entities; got %+v", got)
+	}
+	if elevator.Alert.HeaderText != elevator.Alert.DescriptionText || elevator.Alert.ActivePeriods != nil {
+		t.Errorf("unexpected alert, %+v", elevator.Alert)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	feed := FeedMessage{
+		Header: FeedHeader{Timestamp: 1792250100},
+		Entities: []FeedEntity{
+			{
+				ID: "SAT-7-1",
+				TripUpdate: &TripUpdate{
+					Trip: TripDescriptor{TripID: "SAT-7-1", RouteID: "7", DirectionID: 1, StartDate: "20261017", ScheduleRelationship: TripAdded},
+					StopTimeUpdates: []StopTimeUpdate{
+						{StopID: "MCAR", Departure: &StopTimeEvent{Delay: -30, Time: 1792250100}},
+						{StopID: "12TH", ScheduleRelationship: StopSkipped},
+					},
+				},
+			},
+			{
+				ID: "229331",
+				Alert: &Alert{
+					ActivePeriods:    []TimeRange{{Start: 1792249320}},
+					InformedEntities: []EntitySelector{{StopID: "WOAK"}},
+					Effect:           EffectSignificantDelays,
+					HeaderText:       "10-min delay at WOAK.",
+				},
+			},
+		},
+	}
+	msg := decode(t, feed.Marshal())
+
+	header := msg.message(t, 1)
+	if got := header.string(t, 1); got != Version {
+		t.Errorf("wrong version; got %q, expected %q", got, Version)
+	}
+	if got := header.uint(t, 3); got != 1792250100 {
+		t.Errorf("wrong timestamp; got %d, expected %d", got, 1792250100)
+	}
+
+	entities := msg.messages(t, 2)
+	if len(entities) != 2 {
+		t.Fatalf("wrong number of entities; got %d, expected %d", len(entities), 2)
+	}
+
+	update := entities[0].message(t, 3)
+	trip := update.message(t, 1)
+	if got := trip.string(t, 1); got != "SAT-7-1" {
+		t.Errorf("wrong trip_id; got %q, expected %q", got, "SAT-7-1")
+	}
+	if got := trip.string(t, 3); got != "20261017" {
+		t.Errorf("wrong start_date; got %q, expected %q", got, "20261017")
+	}
+	if got := trip.uint(t, 4); got != TripAdded {
+		t.Errorf("wrong schedule_relationship; got %d, expected %d", got, TripAdded)
+	}
+	if got := trip.uint(t, 6); got != 1 {
+		t.Errorf("wrong direction_id; got %d, expected %d", got, 1)
+	}
+	stus := update.messages(t, 2)
+	if len(stus) != 2 {
+		t.Fatalf("wrong number of stop time updates; got %d, expected %d", len(stus), 2)
+	}
+	departure := stus[0].message(t, 3)
+	if got := int32(departure.uint(t, 1)); got != -30 {
+		t.Errorf("wrong delay; got %d, expected %d", got, -30)
+	}
+	if got := stus[1].uint(t, 5); got != StopSkipped {
+		t.Errorf("wrong schedule_relationship; got %d, expected %d", got, StopSkipped)
+	}
+	if _, ok := stus[1][3]; ok {
+		t.Error("expected no departure for a skipped stop")
+	}
+
+	alert := entities[1].message(t, 5)
+	if got := alert.message(t, 1).uint(t, 1); got != 1792249320 {
+		t.Errorf("wrong active_period start; got %d, expected %d", got, 1792249320)
+	}
+	if got := alert.message(t, 5).string(t, 5); got != "WOAK" {
+		t.Errorf("wrong informed stop_id; got %q, expected %q", got, "WOAK")
+	}
+	if got := alert.uint(t, 7); got != EffectSignificantDelays {
+		t.Errorf("wrong effect; got %d, expected %d", got, EffectSignificantDelays)
+	}
+	translation := alert.message(t, 10).message(t, 1)
+	if got := translation.string(t, 1); got != "10-min delay at WOAK." {
+		t.Errorf("wrong header_text; got %q, expected %q", got, "10-min delay at WOAK.")
+	}
+	if got := translation.string(t, 2); got != "en" {
+		t.Errorf("wrong language; got %q, expected %q", got, "en")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	upstream := barttest.NewServer()
+	defer upstream.Close()
+	handler := NewHandler(upstream.Client(nil), static())
+	handler.now = func() time.Time { return now }
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(t *testing.T, path string) (*http.Response, []byte) {
+		t.Helper()
+		res, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, body
+	}
+
+	t.Run("trip-updates", func(t *testing.T) {
+		upstream.Reset()
+		res, body := get(t, "/trip-updates")
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("wrong status; got %d, expected %d", res.StatusCode, http.StatusOK)
+		}
+		if got := res.Header.Get("Content-Type"); got != ContentType {
+			t.Errorf("wrong Content-Type; got %q, expected %q", got, ContentType)
+		}
+		if n := len(decode(t, body).messages(t, 2)); n == 0 {
+			t.Error("expected some entities")
+		}
+		upstream.AssertRequested(t, "etd", map[string]string{"orig": "ALL"})
+		upstream.AssertRequested(t, "routeinfo", map[string]string{"route": "all"})
+	})
+
+	t.Run("alerts", func(t *testing.T) {
+		res, body := get(t, "/alerts")
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("wrong status; got %d, expected %d", res.StatusCode, http.StatusOK)
+		}
+		entities := decode(t, body).messages(t, 2)
+		if len(entities) != 1 {
+			t.Fatalf("wrong number of entities; got %d, expected %d", len(entities), 1)
+		}
+		if got := entities[0].string(t, 1); got != "229331" {
+			t.Errorf("wrong ID; got %q, expected %q", got, "229331")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		upstream.FailNext("bsa", barttest.Failure{Shape: barttest.ErrorJSONObject, StatusCode: http.StatusServiceUnavailable, Text: "Service unavailable"})
+		if res, _ := get(t, "/alerts"); res.StatusCode != http.StatusBadGateway {
+			t.Errorf("wrong status; got %d, expected %d", res.StatusCode, http.StatusBadGateway)
+		}
+		if res, _ := get(t, "/vehicle-positions"); res.StatusCode != http.StatusNotFound {
+			t.Errorf("wrong status; got %d, expected %d", res.StatusCode, http.StatusNotFound)
+		}
+		res, err := http.Post(server.URL+"/alerts", "text/plain", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("wrong status; got %d, expected %d", res.StatusCode, http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// fields is a decoded protocol buffers message. Values are uint64 for varint
+// fields, and []byte for length-delimited fields.
+type fields map[int][]interface{}
+
+func decode(t *testing.T, buf []byte) fields {
+	t.Helper()
+	out := make(fields)
+	varint := func() uint64 {
+		var val uint64
+		for shift := uint(0); ; shift += 7 {
+			if len(buf) == 0 {
+				t.Fatal("unexpected end of message")
+			}
+			b := buf[0]
+			buf = buf[1:]
+			val |= uint64(b&0x7f) << shift
+			if b < 0x80 {
+				return val
+			}
+		}
+	}
+	for len(buf) > 0 {
+		tag := varint()
+		field := int(tag >> 3)
+		switch tag & 7 {
+		case wireVarint:
+			out[field] = append(out[field], varint())
+		case wireBytes:
+			n := varint()
+			if uint64(len(buf)) < n {
+				t.Fatal("unexpected end of message")
+			}
+			out[field] = append(out[field], buf[:n])
+			buf = buf[n:]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+	}
+	return out
+}
+
+func (f fields) value(t *testing.T, field int) interface{} {
+	t.Helper()
+	vals := f[field]
+	if len(vals) != 1 {
+		t.Fatalf("expected field %d once, got it %d times", field, len(vals))
+	}
+	return vals[0]
+}
+
+func (f fields) uint(t *testing.T, field int) uint64 {
+	t.Helper()
+	return f.value(t, field).(uint64)
+}
+
+func (f fields) string(t *testing.T, field int) string {
+	t.Helper()
+	return string(f.value(t, field).([]byte))
+}
+
+func (f fields) message(t *testing.T, field int) fields {
+	t.Helper()
+	return decode(t, f.value(t, field).([]byte))
+}
+
+func (f fields) messages(t *testing.T, field int) (out []fields) {
+	t.Helper()
+	for _, val := range f[field] {
+		out = append(out, decode(t, val.([]byte)))
+	}
+	return
+}
@@ -0,0 +1,103 @@
+package realtime
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+	"github.com/rafaelespinoza/bart-go/bart/gtfs"
+)
+
+// schedule is the trips of a static feed, indexed for matching trains to them.
+type schedule struct {
+	feed  *gtfs.Feed
+	trips map[string]gtfs.Trip
+	// stopTimes is the departure from each stop of each trip, by trip ID, then
+	// stop ID, in seconds since the start of the service day.
+	stopTimes map[string]map[string]int
+	// departures lists the trips leaving each stop of each route, by route ID
+	// and stop ID.
+	departures map[[2]string][]string
+	// services is the services running on each date, as it's needed.
+	services map[string]map[string]bool
+}
+
+func newSchedule(feed *gtfs.Feed) *schedule {
+	out := &schedule{
+		feed:       feed,
+		trips:      make(map[string]gtfs.Trip),
+		stopTimes:  make(map[string]map[string]int),
+		departures: make(map[[2]string][]string),
+		services:   make(map[string]map[string]bool),
+	}
+	if feed == nil {
+		return out
+	}
+	for _, trip := range feed.Trips {
+		out.trips[trip.ID] = trip
+	}
+	for _, st := range feed.StopTimes {
+		trip, ok := out.trips[st.TripID]
+		if !ok {
+			continue
+		}
+		secs, err := gtfs.ParseTime(st.DepartureTime)
+		if err != nil {
+			continue
+		}
+		stop := strings.ToUpper(st.StopID)
+		if out.stopTimes[trip.ID] == nil {
+			out.stopTimes[trip.ID] = make(map[string]int)
+		}
+		out.stopTimes[trip.ID][stop] = secs
+		key := [2]string{trip.RouteID, stop}
+		out.departures[key] = append(out.departures[key], trip.ID)
+	}
+	return out
+}
+
+// match finds the trip on the route which is scheduled to leave stop closest to
+// at, and no more than maxSlip from it, and its service date. A train leaving
+// soon after midnight may be on the previous day's service.
+func (s *schedule) match(routeID, stop string, at time.Time) (trip gtfs.Trip, date time.Time, ok bool) {
+	at = at.In(bart.Location)
+	best := maxSlip + time.Second
+	for _, day := range []time.Time{bart.CivilDate(at), bart.CivilDate(at).AddDate(0, 0, -1)} {
+		services := s.servicesOn(day)
+		for _, id := range s.departures[[2]string{routeID, stop}] {
+			if !services[s.trips[id].ServiceID] {
+				continue
+			}
+			diff := at.Sub(serviceTime(day, s.stopTimes[id][stop]))
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff < best {
+				trip, date, ok, best = s.trips[id], day, true, diff
+			}
+		}
+	}
+	return
+}
+
+func (s *schedule) servicesOn(day time.Time) map[string]bool {
+	key := day.Format(gtfs.DateLayout)
+	if out, ok := s.services[key]; ok {
+		return out
+	}
+	out := make(map[string]bool)
+	if s.feed != nil {
+		for _, id := range s.feed.ServicesOn(day) {
+			out[id] = true
+		}
+	}
+	s.services[key] = out
+	return out
+}
+
+// serviceTime is the time secs after the start of the service day on date. Like
+// stop times, it's counted on the clock, so it's right on the days when
+// daylight saving time starts or ends.
+func serviceTime(date time.Time, secs int) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, secs, 0, bart.Location)
+}
@@ -0,0 +1,178 @@
+package gtfs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ValidationError lists the problems found by Validate.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return "gtfs: invalid feed: " + e.Problems[0]
+	}
+	return fmt.Sprintf("gtfs: invalid feed, %d problems: %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// Validate checks the referential integrity of the Feed: IDs are unique, and
+// every reference to an agency, route, service, trip or stop is to one which
+// exists. It also checks that each trip has at least two stops, in order, and
+// that times and dates are well-formed. If there are any problems, then the
+// error is a *ValidationError listing all of them.
+func (f *Feed) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	unique := func(file, kind string, ids []string) map[string]bool {
+		out := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if id == "" {
+				addf("%s: empty %s", file, kind)
+			} else if out[id] {
+				addf("%s: duplicate %s %q", file, kind, id)
+			}
+			out[id] = true
+		}
+		return out
+	}
+
+	if len(f.Agencies) == 0 {
+		addf("agency.txt: no agencies")
+	}
+	agencyIDs := make([]string, len(f.Agencies))
+	for i, a := range f.Agencies {
+		agencyIDs[i] = a.ID
+		if a.Name == "" || a.URL == "" || a.Timezone == "" {
+			addf("agency.txt: agency %q is missing a name, url or timezone", a.ID)
+		} else if _, err := time.LoadLocation(a.Timezone); err != nil {
+			addf("agency.txt: agency %q has invalid timezone %q", a.ID, a.Timezone)
+		}
+	}
+	agencies := unique("agency.txt", "agency_id", agencyIDs)
+
+	stopIDs := make([]string, len(f.Stops))
+	for i, s := range f.Stops {
+		stopIDs[i] = s.ID
+		if s.Lat < -90 || s.Lat > 90 || s.Lon < -180 || s.Lon > 180 {
+			addf("stops.txt: stop %q has invalid location %v,%v", s.ID, s.Lat, s.Lon)
+		}
+	}
+	stops := unique("stops.txt", "stop_id", stopIDs)
+
+	routeIDs := make([]string, len(f.Routes))
+	for i, r := range f.Routes {
+		routeIDs[i] = r.ID
+		if !agencies[r.AgencyID] {
+			addf("routes.txt: route %q refers to unknown agency %q", r.ID, r.AgencyID)
+		}
+		if r.ShortName == "" && r.LongName == "" {
+			addf("routes.txt: route %q has no name", r.ID)
+		}
+	}
+	routes := unique("routes.txt", "route_id", routeIDs)
+
+	serviceIDs := make([]string, len(f.Calendars))
+	for i, c := range f.Calendars {
+		serviceIDs[i] = c.ServiceID
+		start, startErr := time.Parse(DateLayout, c.StartDate)
+		end, endErr := time.Parse(DateLayout, c.EndDate)
+		if startErr != nil || endErr != nil {
+			addf("calendar.txt: service %q has invalid dates %q, %q", c.ServiceID, c.StartDate, c.EndDate)
+		} else if end.Before(start) {
+			addf("calendar.txt: service %q ends before it starts", c.ServiceID)
+		}
+	}
+	services := unique("calendar.txt", "service_id", serviceIDs)
+	for _, cd := range f.CalendarDates {
+		services[cd.ServiceID] = true
+		if _, err := time.Parse(DateLayout, cd.Date); err != nil {
+			addf("calendar_dates.txt: service %q has invalid date %q", cd.ServiceID, cd.Date)
+		}
+		if cd.ExceptionType != ServiceAdded && cd.ExceptionType != ServiceRemoved {
+			addf("calendar_dates.txt: service %q has invalid exception_type %d", cd.ServiceID, cd.ExceptionType)
+		}
+	}
+
+	tripIDs := make([]string, len(f.Trips))
+	for i, t := range f.Trips {
+		tripIDs[i] = t.ID
+		if !routes[t.RouteID] {
+			addf("trips.txt: trip %q refers to unknown route %q", t.ID, t.RouteID)
+		}
+		if !services[t.ServiceID] {
+			addf("trips.txt: trip %q refers to unknown service %q", t.ID, t.ServiceID)
+		}
+	}
+	trips := unique("trips.txt", "trip_id", tripIDs)
+
+	type tripState struct {
+		stops    int
+		sequence int
+		secs     int
+	}
+	seen := make(map[string]*tripState)
+	for _, st := range f.StopTimes {
+		if !trips[st.TripID] {
+			addf("stop_times.txt: unknown trip %q", st.TripID)
+			continue
+		}
+		if !stops[st.StopID] {
+			addf("stop_times.txt: trip %q refers to unknown stop %q", st.TripID, st.StopID)
+		}
+		arr, arrErr := ParseTime(st.ArrivalTime)
+		dep, depErr := ParseTime(st.DepartureTime)
+		if arrErr != nil || depErr != nil {
+			addf("stop_times.txt: trip %q has invalid times %q, %q at stop %d", st.TripID, st.ArrivalTime, st.DepartureTime, st.StopSequence)
+			continue
+		}
+		state, ok := seen[st.TripID]
+		if !ok {
+			state = &tripState{sequence: -1}
+			seen[st.TripID] = state
+		}
+		if st.StopSequence <= state.sequence {
+			addf("stop_times.txt: trip %q has stop_sequence %d after %d", st.TripID, st.StopSequence, state.sequence)
+		}
+		if dep < arr || (state.stops > 0 && arr < state.secs) {
+			addf("stop_times.txt: trip %q goes back in time at stop %d", st.TripID, st.StopSequence)
+		}
+		state.stops++
+		state.sequence = st.StopSequence
+		state.secs = dep
+	}
+	for _, t := range f.Trips {
+		if state := seen[t.ID]; state == nil || state.stops < 2 {
+			addf("stop_times.txt: trip %q has fewer than 2 stops", t.ID)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ParseTime parses a time formatted like "HH:MM:SS", where the hours may be 24
+// or more, into seconds since the start of the service day. It's the inverse
+// of the format of StopTime.ArrivalTime and StopTime.DepartureTime.
+func ParseTime(val string) (int, error) {
+	var h, m, s int
+	if n, err := fmt.Sscanf(val, "%d:%d:%d", &h, &m, &s); err != nil || n != 3 {
+		return 0, fmt.Errorf("invalid time %q", val)
+	}
+	if h < 0 || m < 0 || m > 59 || s < 0 || s > 59 {
+		return 0, fmt.Errorf("invalid time %q", val)
+	}
+	return h*3600 + m*60 + s, nil
+}
+
+// formatTime formats seconds since the start of the service day like
+// "HH:MM:SS".
+func formatTime(secs int) string {
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
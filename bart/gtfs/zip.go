@@ -0,0 +1,314 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// feedFile describes how one file of a Feed is written and read.
+type feedFile struct {
+	name     string
+	required bool
+	header   []string
+	rows     func(f *Feed) [][]string
+	read     func(f *Feed, r *record)
+}
+
+// feedFiles are the files of a Feed, in the order they're written.
+var feedFiles = []feedFile{
+	{
+		name:     "agency.txt",
+		required: true,
+		header:   []string{"agency_id", "agency_name", "agency_url", "agency_timezone", "agency_lang", "agency_phone"},
+		rows: func(f *Feed) (out [][]string) {
+			for _, a := range f.Agencies {
+				out = append(out, []string{a.ID, a.Name, a.URL, a.Timezone, a.Lang, a.Phone})
+			}
+			return
+		},
+		read: func(f *Feed, r *record) {
+			f.Agencies = append(f.Agencies, Agency{
+				ID:       r.get("agency_id"),
+				Name:     r.get("agency_name"),
+				URL:      r.get("agency_url"),
+				Timezone: r.get("agency_timezone"),
+				Lang:     r.get("agency_lang"),
+				Phone:    r.get("agency_phone"),
+			})
+		},
+	},
+	{
+		name:     "stops.txt",
+		required: true,
+		header:   []string{"stop_id", "stop_name", "stop_lat", "stop_lon"},
+		rows: func(f *Feed) (out [][]string) {
+			for _, s := range f.Stops {
+				out = append(out, []string{s.ID, s.Name, formatFloat(s.Lat), formatFloat(s.Lon)})
+			}
+			return
+		},
+		read: func(f *Feed, r *record) {
+			f.Stops = append(f.Stops, Stop{
+				ID:   r.get("stop_id"),
+				Name: r.get("stop_name"),
+				Lat:  r.float("stop_lat"),
+				Lon:  r.float("stop_lon"),
+			})
+		},
+	},
+	{
+		name:     "routes.txt",
+		required: true,
+		header:   []string{"route_id", "agency_id", "route_short_name", "route_long_name", "route_type", "route_color", "route_text_color"},
+		rows: func(f *Feed) (out [][]string) {
+			for _, rt := range f.Routes {
+				out = append(out, []string{rt.ID, rt.AgencyID, rt.ShortName, rt.LongName, strconv.Itoa(rt.Type), rt.Color, rt.TextColor})
+			}
+			return
+		},
+		read: func(f *Feed, r *record) {
+			f.Routes = append(f.Routes, Route{
+				ID:        r.get("route_id"),
+				AgencyID:  r.get("agency_id"),
+				ShortName: r.get("route_short_name"),
+				LongName:  r.get("route_long_name"),
+				Type:      r.int("route_type"),
+				Color:     r.get("route_color"),
+				TextColor: r.get("route_text_color"),
+			})
+		},
+	},
+	{
+		name:     "trips.txt",
+		required: true,
+		header:   []string{"route_id", "service_id", "trip_id", "trip_headsign", "direction_id"},
+		rows: func(f *Feed) (out [][]string) {
+			for _, t := range f.Trips {
+				out = append(out, []string{t.RouteID, t.ServiceID, t.ID, t.Headsign, strconv.Itoa(t.DirectionID)})
+			}
+			return
+		},
+		read: func(f *Feed, r *record) {
+			f.Trips = append(f.Trips, Trip{
+				RouteID:     r.get("route_id"),
+				ServiceID:   r.get("service_id"),
+				ID:          r.get("trip_id"),
+				Headsign:    r.get("trip_headsign"),
+				DirectionID: r.int("direction_id"),
+			})
+		},
+	},
+	{
+		name:     "stop_times.txt",
+		required: true,
+		header:   []string{"trip_id", "arrival_time", "departure_time", "stop_id", "stop_sequence"},
+		rows: func(f *Feed) (out [][]string) {
+			for _, st := range f.StopTimes {
+				out = append(out, []string{st.TripID, st.ArrivalTime, st.DepartureTime, st.StopID, strconv.Itoa(st.StopSequence)})
+			}
+			return
+		},
+		read: func(f *Feed, r *record) {
+			f.StopTimes = append(f.StopTimes, StopTime{
+				TripID:        r.get("trip_id"),
+				ArrivalTime:   r.get("arrival_time"),
+				DepartureTime: r.get("departure_time"),
+				StopID:        r.get("stop_id"),
+				StopSequence:  r.int("stop_sequence"),
+			})
+		},
+	},
+	{
+		name:   "calendar.txt",
+		header: []string{"service_id", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "start_date", "end_date"},
+		rows: func(f *Feed) (out [][]string) {
+			for _, c := range f.Calendars {
+				row := []string{c.ServiceID}
+				for _, day := range calendarWeekdays {
+					row = append(row, formatBool(c.Weekdays[day]))
+				}
+				out = append(out, append(row, c.StartDate, c.EndDate))
+			}
+			return
+		},
+		read: func(f *Feed, r *record) {
+			c := Calendar{
+				ServiceID: r.get("service_id"),
+				StartDate: r.get("start_date"),
+				EndDate:   r.get("end_date"),
+			}
+			for _, day := range calendarWeekdays {
+				c.Weekdays[day] = r.int(strings.ToLower(day.String())) == 1
+			}
+			f.Calendars = append(f.Calendars, c)
+		},
+	},
+	{
+		name:   "calendar_dates.txt",
+		header: []string{"service_id", "date", "exception_type"},
+		rows: func(f *Feed) (out [][]string) {
+			for _, cd := range f.CalendarDates {
+				out = append(out, []string{cd.ServiceID, cd.Date, strconv.Itoa(cd.ExceptionType)})
+			}
+			return
+		},
+		read: func(f *Feed, r *record) {
+			f.CalendarDates = append(f.CalendarDates, CalendarDate{
+				ServiceID:     r.get("service_id"),
+				Date:          r.get("date"),
+				ExceptionType: r.int("exception_type"),
+			})
+		},
+	},
+}
+
+// WriteZip writes the Feed to w as a zip file. The calendar.txt and
+// calendar_dates.txt files are left out when they're empty, since a feed only
+// needs one of them.
+func (f *Feed) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, file := range feedFiles {
+		rows := file.rows(f)
+		if len(rows) == 0 && !file.required {
+			continue
+		}
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		cw := csv.NewWriter(fw)
+		if err = cw.Write(file.header); err != nil {
+			return err
+		}
+		if err = cw.WriteAll(rows); err != nil {
+			return fmt.Errorf("gtfs: writing %s: %w", file.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// ReadZip reads a Feed from a zip file, such as one written by WriteZip. Files
+// and columns which aren't part of a Feed are ignored. It's an error if one of
+// the required files is missing, but the Feed isn't validated otherwise. Use
+// Validate for that.
+func ReadZip(r io.ReaderAt, size int64) (*Feed, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	out := &Feed{}
+	for _, file := range feedFiles {
+		zf, err := zr.Open(file.name)
+		if err != nil {
+			if file.required {
+				return nil, fmt.Errorf("gtfs: missing %s", file.name)
+			}
+			continue
+		}
+		err = readFile(out, file, zf)
+		zf.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func readFile(f *Feed, file feedFile, in io.Reader) error {
+	cr := csv.NewReader(in)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("gtfs: reading %s: %w", file.name, err)
+	}
+	if len(header) > 0 {
+		// The header may start with a byte order mark.
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+
+	for line := 2; ; line++ {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("gtfs: reading %s: %w", file.name, err)
+		}
+		r := &record{columns: columns, fields: fields}
+		file.read(f, r)
+		if r.err != nil {
+			return fmt.Errorf("gtfs: %s line %d: %w", file.name, line, r.err)
+		}
+	}
+}
+
+// record is a line of a file, with its fields looked up by column name.
+type record struct {
+	columns map[string]int
+	fields  []string
+	// err is the first error parsing a field.
+	err error
+}
+
+func (r *record) get(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.fields) {
+		return ""
+	}
+	return r.fields[i]
+}
+
+func (r *record) int(column string) int {
+	val := r.get(column)
+	if val == "" {
+		return 0
+	}
+	out, err := strconv.Atoi(val)
+	if err != nil {
+		r.setErr(fmt.Errorf("invalid %s %q", column, val))
+	}
+	return out
+}
+
+func (r *record) float(column string) float64 {
+	val := r.get(column)
+	if val == "" {
+		return 0
+	}
+	out, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		r.setErr(fmt.Errorf("invalid %s %q", column, val))
+	}
+	return out
+}
+
+func (r *record) setErr(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// calendarWeekdays are the days of the week, in the order of the columns in
+// calendar.txt.
+var calendarWeekdays = [7]time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+func formatFloat(val float64) string { return strconv.FormatFloat(val, 'f', -1, 64) }
+
+func formatBool(val bool) string {
+	if val {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,178 @@
+// Package ical encodes BART trip plans as iCalendar events, so they can be
+// shared with calendar apps. TripEvent makes an event of a Trip, Commute makes
+// one for each weekday between two dates, and Encode writes them as an
+// iCalendar object. See RFC 5545 for the format.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+)
+
+// ContentType is the media type of an iCalendar object.
+const ContentType = "text/calendar; charset=utf-8"
+
+// ProdID identifies this package as the maker of an iCalendar object.
+const ProdID = "-//rafaelespinoza//bart-go//EN"
+
+// An Event is one VEVENT in an iCalendar object. Start and End are written in
+// bart.Location. Stamp is when the event was made, and the zero-value means
+// the time it's encoded.
+type Event struct {
+	UID         string
+	Stamp       time.Time
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Location    string
+	Description string
+	Alarms      []Alarm
+}
+
+// An Alarm is a VALARM in an Event, which displays Description at Trigger
+// after the start of the event. A negative Trigger is before the start.
+type Alarm struct {
+	Trigger     time.Duration
+	Description string
+}
+
+// Encode writes events as one iCalendar object, with the time zone of
+// bart.Location.
+func Encode(w io.Writer, events []Event) error {
+	e := contentWriter{w: bufio.NewWriter(w)}
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", ProdID)
+	e.line("CALSCALE", "GREGORIAN")
+	e.line("METHOD", "PUBLISH")
+	e.timezone()
+	now := time.Now()
+	for _, ev := range events {
+		stamp := ev.Stamp
+		if stamp.IsZero() {
+			stamp = now
+		}
+		e.line("BEGIN", "VEVENT")
+		e.line("UID", ev.UID)
+		e.line("DTSTAMP", stamp.UTC().Format(utcLayout))
+		e.line("DTSTART;TZID="+tzid(), ev.Start.In(bart.Location).Format(localLayout))
+		e.line("DTEND;TZID="+tzid(), ev.End.In(bart.Location).Format(localLayout))
+		e.text("SUMMARY", ev.Summary)
+		e.text("LOCATION", ev.Location)
+		e.text("DESCRIPTION", ev.Description)
+		for _, alarm := range ev.Alarms {
+			e.line("BEGIN", "VALARM")
+			e.line("ACTION", "DISPLAY")
+			e.line("TRIGGER", formatDuration(alarm.Trigger))
+			e.text("DESCRIPTION", alarm.Description)
+			e.line("END", "VALARM")
+		}
+		e.line("END", "VEVENT")
+	}
+	e.line("END", "VCALENDAR")
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// Layouts of DATE-TIME values. Local times refer to a VTIMEZONE by its TZID.
+const (
+	localLayout = "20060102T150405"
+	utcLayout   = "20060102T150405Z"
+)
+
+func tzid() string { return bart.Location.String() }
+
+// timezone writes the VTIMEZONE for bart.Location, with the rules for daylight
+// saving time in the US since 2007.
+func (e *contentWriter) timezone() {
+	e.line("BEGIN", "VTIMEZONE")
+	e.line("TZID", tzid())
+	e.line("BEGIN", "DAYLIGHT")
+	e.line("TZOFFSETFROM", "-0800")
+	e.line("TZOFFSETTO", "-0700")
+	e.line("TZNAME", "PDT")
+	e.line("DTSTART", "20070311T020000")
+	e.line("RRULE", "FREQ=YEARLY;BYMONTH=3;BYDAY=2SU")
+	e.line("END", "DAYLIGHT")
+	e.line("BEGIN", "STANDARD")
+	e.line("TZOFFSETFROM", "-0700")
+	e.line("TZOFFSETTO", "-0800")
+	e.line("TZNAME", "PST")
+	e.line("DTSTART", "20071104T020000")
+	e.line("RRULE", "FREQ=YEARLY;BYMONTH=11;BYDAY=1SU")
+	e.line("END", "STANDARD")
+	e.line("END", "VTIMEZONE")
+}
+
+// formatDuration formats d as a DURATION value, such as "-PT2M".
+func formatDuration(d time.Duration) string {
+	var sign string
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	d = d.Round(time.Second)
+	h, m, s := int(d/time.Hour), int(d%time.Hour/time.Minute), int(d%time.Minute/time.Second)
+	out := sign + "PT"
+	if h > 0 {
+		out += fmt.Sprintf("%dH", h)
+	}
+	if m > 0 {
+		out += fmt.Sprintf("%dM", m)
+	}
+	if s > 0 || (h == 0 && m == 0) {
+		out += fmt.Sprintf("%dS", s)
+	}
+	return out
+}
+
+// maxLineLength is the most octets in a content line, not counting the line
+// break. Longer lines are folded.
+const maxLineLength = 75
+
+// contentWriter writes content lines. The first error is kept, and later
+// writes are skipped.
+type contentWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// text writes a property with a TEXT value, escaping it. Empty values are
+// skipped.
+func (e *contentWriter) text(name, val string) {
+	if val == "" {
+		return
+	}
+	e.line(name, textEscaper.Replace(val))
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// line writes a content line, folding it so no line is longer than
+// maxLineLength octets. Lines are only folded between characters.
+func (e *contentWriter) line(name, val string) {
+	if e.err != nil {
+		return
+	}
+	line := name + ":" + val
+	var out strings.Builder
+	for n := 0; len(line) > 0; {
+		_, size := utf8.DecodeRuneInString(line)
+		if n+size > maxLineLength {
+			out.WriteString("\r\n ")
+			n = 1 // The leading space counts.
+		}
+		out.WriteString(line[:size])
+		n += size
+		line = line[size:]
+	}
+	out.WriteString("\r\n")
+	_, e.err = e.w.WriteString(out.String())
+}
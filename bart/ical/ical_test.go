@@ -0,0 +1,202 @@
+package ical
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+	"github.com/rafaelespinoza/bart-go/bart/barttest"
+)
+
+// fixtures is a trip on a weekday, and the holidays. The trips in the depart
+// fixture are on a Saturday, 10/17/2026, so they're moved to the Monday after.
+func fixtures(t *testing.T) (bart.Trip, bart.HolidaySchedulesResponse) {
+	t.Helper()
+	server := barttest.NewServer()
+	defer server.Close()
+	client := server.Client(nil)
+
+	depart, err := barttest.Fixture("depart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.SetFixture("depart", bytes.ReplaceAll(depart, []byte("10/17/2026"), []byte("10/19/2026")))
+	trips, err := client.RequestDepartures(bart.TripParams{Orig: "SFIA", Dest: "CAST"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	holidays, err := client.RequestHolidaySchedules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trips.Root.Data.Request.List[0], holidays
+}
+
+func TestTripEvent(t *testing.T) {
+	trip, _ := fixtures(t)
+	event, err := TripEvent(trip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Event{
+		UID:      "20261019T0808-SFIA-CAST@bart-go",
+		Start:    time.Date(2026, 10, 19, 8, 8, 0, 0, bart.Location),
+		End:      time.Date(2026, 10, 19, 9, 9, 0, 0, bart.Location),
+		Summary:  "BART: San Francisco International Airport to Castro Valley",
+		Location: "San Francisco International Airport",
+		Description: "1. 8:08 AM San Francisco International Airport to 8:25 AM Balboa Park, on the Antioch train\n" +
+			"2. 8:31 AM Balboa Park to 9:09 AM Castro Valley, on the Dublin/Pleasanton train",
+		Alarms: []Alarm{{
+			Trigger:     15 * time.Minute,
+			Description: "Transfer at Balboa Park to the Dublin/Pleasanton train, leaving at 8:31 AM",
+		}},
+	}
+	if !event.Start.Equal(expected.Start) || !event.End.Equal(expected.End) {
+		t.Errorf("wrong times; got %s - %s, expected %s - %s", event.Start, event.End, expected.Start, expected.End)
+	}
+	event.Start, event.End = expected.Start, expected.End
+	if !reflect.DeepEqual(event, expected) {
+		t.Errorf("wrong event;\ngot      %+v\nexpected %+v", event, expected)
+	}
+}
+
+func TestCommute(t *testing.T) {
+	trip, holidays := fixtures(t)
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		end      time.Time
+		expected []string
+	}{
+		{
+			// Thanksgiving and the day after are holidays.
+			name:     "holidays",
+			start:    time.Date(2026, 11, 22, 0, 0, 0, 0, bart.Location),
+			end:      time.Date(2026, 12, 1, 0, 0, 0, 0, bart.Location),
+			expected: []string{"2026-11-23 08:08", "2026-11-24 08:08", "2026-11-25 08:08", "2026-11-30 08:08", "2026-12-01 08:08"},
+		},
+		{
+			// Daylight saving time ends on 11/01/2026.
+			name:     "dst",
+			start:    time.Date(2026, 10, 30, 0, 0, 0, 0, bart.Location),
+			end:      time.Date(2026, 11, 2, 0, 0, 0, 0, bart.Location),
+			expected: []string{"2026-10-30 08:08", "2026-11-02 08:08"},
+		},
+		{
+			name:  "weekend",
+			start: time.Date(2026, 10, 17, 0, 0, 0, 0, bart.Location),
+			end:   time.Date(2026, 10, 18, 0, 0, 0, 0, bart.Location),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events, err := Commute(trip, test.start, test.end, holidays)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			uids := make(map[string]bool)
+			for _, ev := range events {
+				got = append(got, ev.Start.In(bart.Location).Format("2006-01-02 15:04"))
+				if d := ev.End.Sub(ev.Start); d != 61*time.Minute {
+					t.Errorf("wrong duration; got %s, expected %s", d, 61*time.Minute)
+				}
+				if uids[ev.UID] {
+					t.Errorf("duplicate UID %q", ev.UID)
+				}
+				uids[ev.UID] = true
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("wrong dates; got %q, expected %q", got, test.expected)
+			}
+		})
+	}
+
+	t.Run("dates", func(t *testing.T) {
+		_, err := Commute(trip, time.Date(2026, 11, 2, 0, 0, 0, 0, bart.Location), time.Date(2026, 11, 1, 0, 0, 0, 0, bart.Location), holidays)
+		if err == nil {
+			t.Error("expected an error when end is before start")
+		}
+	})
+
+	t.Run("not weekday service", func(t *testing.T) {
+		start := time.Date(2026, 11, 2, 0, 0, 0, 0, bart.Location)
+		end := time.Date(2026, 11, 6, 0, 0, 0, 0, bart.Location)
+		for _, date := range []string{"10/17/2026", "11/26/2026"} {
+			other := trip
+			other.OrigTimeDate = date
+			if _, err := Commute(other, start, end, holidays); err == nil {
+				t.Errorf("expected an error for a trip on %s", date)
+			}
+		}
+	})
+}
+
+func TestEncode(t *testing.T) {
+	trip, _ := fixtures(t)
+	event, err := TripEvent(trip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event.Stamp = time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC)
+	event.Description += "\nBring a jacket; it's cold, sometimes."
+
+	var buf bytes.Buffer
+	if err = Encode(&buf, []Event{event}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Errorf("expected the object to end with a line break, got %q", out[len(out)-20:])
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	for _, line := range lines {
+		if len(line) > maxLineLength {
+			t.Errorf("line is longer than %d octets, %q", maxLineLength, line)
+		}
+		if strings.Contains(line, "\n") {
+			t.Errorf("line has a bare line break, %q", line)
+		}
+	}
+
+	// Unfold the lines to check their values.
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	for _, expected := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:" + ProdID + "\r\n",
+		"BEGIN:VTIMEZONE\r\nTZID:America/Los_Angeles\r\n",
+		"UID:20261019T0808-SFIA-CAST@bart-go\r\n",
+		"DTSTAMP:20261017T150000Z\r\n",
+		"DTSTART;TZID=America/Los_Angeles:20261019T080800\r\n",
+		"DTEND;TZID=America/Los_Angeles:20261019T090900\r\n",
+		`DESCRIPTION:1. 8:08 AM San Francisco International Airport to 8:25 AM Balboa Park\, on the Antioch train\n2.`,
+		`\nBring a jacket\; it's cold\, sometimes.` + "\r\n",
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER:PT15M\r\n",
+	} {
+		if !strings.Contains(unfolded, expected) {
+			t.Errorf("expected output to contain %q\n%s", expected, out)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in       time.Duration
+		expected string
+	}{
+		{in: 15 * time.Minute, expected: "PT15M"},
+		{in: -2 * time.Minute, expected: "-PT2M"},
+		{in: time.Hour + 30*time.Second, expected: "PT1H30S"},
+		{in: 0, expected: "PT0S"},
+	}
+	for _, test := range tests {
+		if got := formatDuration(test.in); got != test.expected {
+			t.Errorf("%s; got %q, expected %q", test.in, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+)
+
+// TransferWarning is how long before arriving at a transfer station the
+// transfer alarm of an event goes off.
+const TransferWarning = 2 * time.Minute
+
+// TripEvent makes an event of a trip, such as from RequestDepartures. The
+// event lasts from departing the origin station to arriving at the destination
+// station, and its description lists the legs. There's an alarm before each
+// transfer, which says which train to change to.
+func TripEvent(trip bart.Trip) (out Event, err error) {
+	start, err := trip.OrigTime()
+	if err != nil {
+		return
+	}
+	end, err := trip.DestTime()
+	if err != nil {
+		return
+	}
+	out = Event{
+		UID:      tripUID(trip, start),
+		Start:    start,
+		End:      end,
+		Summary:  fmt.Sprintf("BART: %s to %s", stationName(trip.Origin), stationName(trip.Destination)),
+		Location: stationName(trip.Origin),
+	}
+
+	lines := make([]string, len(trip.Legs))
+	for i, leg := range trip.Legs {
+		lines[i] = fmt.Sprintf("%d. %s %s to %s %s, on the %s train",
+			i+1,
+			leg.OrigTimeMin, stationName(leg.Origin),
+			leg.DestTimeMin, stationName(leg.Destination),
+			stationName(leg.TrainHeadStation),
+		)
+		if i == 0 {
+			continue
+		}
+		arrival, err := trip.Legs[i-1].DestTime()
+		if err != nil {
+			return Event{}, err
+		}
+		out.Alarms = append(out.Alarms, Alarm{
+			Trigger: arrival.Sub(start) - TransferWarning,
+			Description: fmt.Sprintf("Transfer at %s to the %s train, leaving at %s",
+				stationName(leg.Origin), stationName(leg.TrainHeadStation), leg.OrigTimeMin),
+		})
+	}
+	out.Description = strings.Join(lines, "\n")
+	return
+}
+
+// Commute makes an event of trip on every weekday between the start and end
+// dates, except for holidays, such as from RequestHolidaySchedules. Only the
+// year, month and day of start and end are used. It's the same trip at the
+// same time of day on each date, since BART runs the same schedule on every
+// weekday. So trip must be on the weekday schedule, such as from
+// RequestDepartures on a weekday which isn't a holiday, or there's an error.
+func Commute(trip bart.Trip, start, end time.Time, holidays bart.HolidaySchedulesResponse) (out []Event, err error) {
+	first, last := bart.CivilDate(start), bart.CivilDate(end)
+	if last.Before(first) {
+		err = fmt.Errorf("ical: end %s is before start %s", last.Format(bart.DateLayout), first.Format(bart.DateLayout))
+		return
+	}
+	skip := make(map[string]bool)
+	for _, data := range holidays.Root.Data {
+		for _, h := range data.List {
+			skip[h.Date] = true
+		}
+	}
+	serviceDate, err := time.ParseInLocation(bart.DateLayout, strings.TrimSpace(trip.OrigTimeDate), bart.Location)
+	if err != nil {
+		return
+	}
+	if !isWeekday(serviceDate, skip) {
+		err = fmt.Errorf("ical: trip on %s is not on the weekday schedule", serviceDate.Format(bart.DateLayout))
+		return
+	}
+	event, err := TripEvent(trip)
+	if err != nil {
+		return
+	}
+
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if isWeekday(day, skip) {
+			out = append(out, event.moved(daysBetween(serviceDate, day), trip))
+		}
+	}
+	return
+}
+
+// isWeekday reports whether BART runs the weekday schedule on day, which is when
+// it's Monday to Friday and not one of the holidays.
+func isWeekday(day time.Time, holidays map[string]bool) bool {
+	wd := day.Weekday()
+	return wd != time.Saturday && wd != time.Sunday && !holidays[day.Format(bart.DateLayout)]
+}
+
+// moved is a copy of the event of trip, days later.
+func (e Event) moved(days int, trip bart.Trip) Event {
+	e.Start = e.Start.AddDate(0, 0, days)
+	e.End = e.End.AddDate(0, 0, days)
+	e.UID = tripUID(trip, e.Start)
+	return e
+}
+
+// tripUID identifies the event of a trip by its stations and departure time.
+func tripUID(trip bart.Trip, start time.Time) string {
+	return fmt.Sprintf("%s-%s-%s@bart-go",
+		start.In(bart.Location).Format("20060102T1504"),
+		strings.ToUpper(trip.Origin), strings.ToUpper(trip.Destination))
+}
+
+// stationName is the name of the station, or the abbreviation if it's unknown.
+func stationName(abbr string) string {
+	if stn, err := bart.LookupStation(abbr); err == nil {
+		return stn.Name
+	}
+	return strings.ToUpper(abbr)
+}
+
+// daysBetween is the number of calendar days from a to b.
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return int(time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC).Sub(time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)) / (24 * time.Hour))
+}
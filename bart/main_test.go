@@ -0,0 +1,18 @@
+package bart_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rafaelespinoza/bart-go/bart/barttest"
+)
+
+// exampleServer is the fake BART API of the examples, so they can run offline.
+var exampleServer *barttest.Server
+
+func TestMain(m *testing.M) {
+	exampleServer = barttest.NewServer()
+	code := m.Run()
+	exampleServer.Close()
+	os.Exit(code)
+}
@@ -0,0 +1,271 @@
+package bart
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNoPath is returned by Network methods when there is no way to get from one
+// station to the other.
+var ErrNoPath = errors.New("bart: no path between stations")
+
+// A Network is the graph of BART stations, connected by the routes running
+// between them. Make one from a response to RequestRoutesInfo with NewNetwork,
+// or use the bundled snapshot with DefaultNetwork. Station abbreviations are
+// accepted in any case, and are upper-case in outputs. A Network is safe for
+// concurrent use, since it's never modified after it's made.
+type Network struct {
+	routes    []RouteInfo
+	adjacent  map[string][]string
+	stnRoutes map[string][]int
+}
+
+// NewNetwork builds a Network from the stations of each route in res.
+func NewNetwork(res RoutesInfoResponse) *Network {
+	n := &Network{
+		routes:    res.Root.Data.List,
+		adjacent:  make(map[string][]string),
+		stnRoutes: make(map[string][]int),
+	}
+
+	edges := make(map[string]map[string]bool)
+	addEdge := func(a, b string) {
+		if edges[a] == nil {
+			edges[a] = make(map[string]bool)
+		}
+		edges[a][b] = true
+	}
+	for i, route := range n.routes {
+		stations := route.Config.Stations
+		for j, abbr := range stations {
+			abbr = strings.ToUpper(abbr)
+			n.stnRoutes[abbr] = append(n.stnRoutes[abbr], i)
+			if j > 0 {
+				prev := strings.ToUpper(stations[j-1])
+				addEdge(prev, abbr)
+				addEdge(abbr, prev)
+			}
+		}
+	}
+	for abbr, neighbors := range edges {
+		for neighbor := range neighbors {
+			n.adjacent[abbr] = append(n.adjacent[abbr], neighbor)
+		}
+		sort.Strings(n.adjacent[abbr])
+	}
+	return n
+}
+
+var (
+	defaultNetwork     *Network
+	defaultNetworkOnce sync.Once
+)
+
+// DefaultNetwork is a Network built from a snapshot of the routes bundled with
+// this package. It may be out of date, so prefer NewNetwork with a fresh
+// response when that matters.
+func DefaultNetwork() *Network {
+	defaultNetworkOnce.Do(func() {
+		var res RoutesInfoResponse
+		data, err := Snapshot("routeinfo")
+		if err == nil {
+			err = json.Unmarshal(data, &res)
+		}
+		if err != nil {
+			panic(fmt.Errorf("bart: invalid routes snapshot: %w", err))
+		}
+		defaultNetwork = NewNetwork(res)
+	})
+	return defaultNetwork
+}
+
+// Stations lists the abbreviations of every station in the Network, sorted.
+func (n *Network) Stations() []string {
+	out := make([]string, 0, len(n.stnRoutes))
+	for abbr := range n.stnRoutes {
+		out = append(out, abbr)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// station validates an abbreviation and makes sure it's in the Network.
+func (n *Network) station(param, abbr string) (string, error) {
+	abbr, err := normalizeStation(param, abbr)
+	if err != nil {
+		return "", err
+	}
+	abbr = strings.ToUpper(abbr)
+	if _, ok := n.stnRoutes[abbr]; !ok {
+		return "", fmt.Errorf("bart: station %s is not on any route", abbr)
+	}
+	return abbr, nil
+}
+
+// Neighbors lists the stations one stop away from a station, sorted.
+func (n *Network) Neighbors(abbr string) ([]string, error) {
+	abbr, err := n.station("orig", abbr)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string(nil), n.adjacent[abbr]...), nil
+}
+
+// Routes lists the routes stopping at a station, in the same order as the
+// response used to build the Network. Each route goes in one direction, so a
+// line usually has two routes.
+func (n *Network) Routes(abbr string) ([]RouteInfo, error) {
+	abbr, err := n.station("orig", abbr)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]RouteInfo, 0, len(n.stnRoutes[abbr]))
+	for _, i := range n.stnRoutes[abbr] {
+		out = append(out, n.routes[i])
+	}
+	return out, nil
+}
+
+// Lines lists the colors of the lines stopping at a station, such as "YELLOW",
+// sorted.
+func (n *Network) Lines(abbr string) ([]string, error) {
+	routes, err := n.Routes(abbr)
+	if err != nil {
+		return nil, err
+	}
+	return lineColors(routes), nil
+}
+
+func lineColors(routes []RouteInfo) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, route := range routes {
+		color := strings.ToUpper(route.Color)
+		if !seen[color] {
+			seen[color] = true
+			out = append(out, color)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// TransferStations lists the stations where more than one line stops, so
+// riders can change lines there, sorted.
+func (n *Network) TransferStations() []string {
+	var out []string
+	for abbr, indexes := range n.stnRoutes {
+		routes := make([]RouteInfo, len(indexes))
+		for i, j := range indexes {
+			routes[i] = n.routes[j]
+		}
+		if len(lineColors(routes)) > 1 {
+			out = append(out, abbr)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Path finds a route with the fewest stops from orig to dest, which are
+// station abbreviations. The output starts with orig and ends with dest. If
+// there are several paths with the fewest stops, then the same one is always
+// chosen. It's ErrNoPath if dest can't be reached from orig.
+func (n *Network) Path(orig, dest string) ([]string, error) {
+	orig, err := n.station("orig", orig)
+	if err != nil {
+		return nil, err
+	}
+	if dest, err = n.station("dest", dest); err != nil {
+		return nil, err
+	}
+
+	prev := map[string]string{orig: ""}
+	queue := []string{orig}
+	for len(queue) > 0 && queue[0] != dest {
+		curr := queue[0]
+		queue = queue[1:]
+		for _, next := range n.adjacent[curr] {
+			if _, seen := prev[next]; !seen {
+				prev[next] = curr
+				queue = append(queue, next)
+			}
+		}
+	}
+	if _, ok := prev[dest]; !ok {
+		return nil, ErrNoPath
+	}
+
+	var out []string
+	for abbr := dest; abbr != ""; abbr = prev[abbr] {
+		out = append(out, abbr)
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
+
+// RequiresTransfer reports whether a trip from orig to dest needs more than one
+// train, because no route stops at orig and then dest.
+func (n *Network) RequiresTransfer(orig, dest string) (bool, error) {
+	orig, err := n.station("orig", orig)
+	if err != nil {
+		return false, err
+	}
+	if dest, err = n.station("dest", dest); err != nil {
+		return false, err
+	}
+	if orig == dest {
+		return false, nil
+	}
+
+	for _, i := range n.stnRoutes[orig] {
+		var seenOrig bool
+		for _, abbr := range n.routes[i].Config.Stations {
+			switch strings.ToUpper(abbr) {
+			case orig:
+				seenOrig = true
+			case dest:
+				if seenOrig {
+					return false, nil
+				}
+			}
+		}
+	}
+	if _, err = n.Path(orig, dest); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// stopsAt reports whether a train leaving orig with the last station terminal
+// stops at dest on the way. The train is on a route which stops at orig, then
+// dest, then terminal, so trains which end early are found too. A non-empty
+// color limits the routes to one line. Abbreviations must be upper-case.
+func (n *Network) stopsAt(orig, dest, terminal, color string) bool {
+	for _, i := range n.stnRoutes[orig] {
+		route := n.routes[i]
+		if color != "" && !strings.EqualFold(route.Color, color) {
+			continue
+		}
+		var seenOrig, seenDest bool
+		for _, abbr := range route.Config.Stations {
+			abbr = strings.ToUpper(abbr)
+			switch {
+			case abbr == orig:
+				seenOrig = true
+			case seenOrig && abbr == dest:
+				seenDest = true
+			}
+			if seenDest && abbr == terminal {
+				return true
+			}
+		}
+	}
+	return false
+}
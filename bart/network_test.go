@@ -0,0 +1,135 @@
+package bart
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNetwork(t *testing.T) {
+	network := DefaultNetwork()
+
+	t.Run("Stations", func(t *testing.T) {
+		if got := len(network.Stations()); got != len(Stations()) {
+			t.Errorf("wrong number of stations; got %d, expected %d", got, len(Stations()))
+		}
+	})
+
+	t.Run("Neighbors", func(t *testing.T) {
+		got, err := network.Neighbors("mcar")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"19TH", "ASHB", "ROCK"}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("wrong neighbors; got %v, expected %v", got, expected)
+		}
+	})
+
+	t.Run("Lines", func(t *testing.T) {
+		tests := []struct {
+			abbr     string
+			expected []string
+		}{
+			{abbr: "antc", expected: []string{"YELLOW"}},
+			{abbr: "MCAR", expected: []string{"ORANGE", "RED", "YELLOW"}},
+			{abbr: "oakl", expected: []string{"BEIGE"}},
+			{abbr: "cols", expected: []string{"BEIGE", "BLUE", "GREEN", "ORANGE"}},
+		}
+		for _, test := range tests {
+			got, err := network.Lines(test.abbr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("%s; wrong lines; got %v, expected %v", test.abbr, got, test.expected)
+			}
+		}
+	})
+
+	t.Run("TransferStations", func(t *testing.T) {
+		got := strings.Join(network.TransferStations(), ",")
+		for _, abbr := range []string{"MCAR", "BAYF", "BALB", "COLS", "12TH"} {
+			if !strings.Contains(got, abbr) {
+				t.Errorf("expected %s to be a transfer station", abbr)
+			}
+		}
+		for _, abbr := range []string{"ANTC", "OAKL", "DUBL"} {
+			if strings.Contains(got, abbr) {
+				t.Errorf("did not expect %s to be a transfer station", abbr)
+			}
+		}
+	})
+
+	t.Run("Path", func(t *testing.T) {
+		got, err := network.Path("ashb", "19th")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"ASHB", "MCAR", "19TH"}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("wrong path; got %v, expected %v", got, expected)
+		}
+
+		got, err = network.Path("oakl", "dubl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[0] != "OAKL" || got[1] != "COLS" || got[len(got)-1] != "DUBL" {
+			t.Errorf("unexpected path, %v", got)
+		}
+
+		got, err = network.Path("embr", "embr")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, []string{"EMBR"}) {
+			t.Errorf("wrong path; got %v, expected %v", got, []string{"EMBR"})
+		}
+	})
+
+	t.Run("RequiresTransfer", func(t *testing.T) {
+		tests := []struct {
+			orig     string
+			dest     string
+			expected bool
+		}{
+			{orig: "antc", dest: "sfia", expected: false},
+			{orig: "sfia", dest: "antc", expected: false},
+			{orig: "dubl", dest: "embr", expected: false},
+			{orig: "dubl", dest: "rich", expected: true},
+			{orig: "oakl", dest: "embr", expected: true},
+			{orig: "sfia", dest: "cast", expected: true},
+		}
+		for _, test := range tests {
+			got, err := network.RequiresTransfer(test.orig, test.dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.expected {
+				t.Errorf("%s to %s; got %t, expected %t", test.orig, test.dest, got, test.expected)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := network.Path("nope", "embr"); !errors.Is(err, ErrInvalidOrig) {
+			t.Errorf("expected %v, got %v", ErrInvalidOrig, err)
+		}
+		if _, err := network.RequiresTransfer("embr", "nope"); !errors.Is(err, ErrInvalidDest) {
+			t.Errorf("expected %v, got %v", ErrInvalidDest, err)
+		}
+
+		var res RoutesInfoResponse
+		res.Root.Data.List = []RouteInfo{
+			{Number: 1, Color: "RED"},
+			{Number: 2, Color: "BLUE"},
+		}
+		res.Root.Data.List[0].Config.Stations = []string{"RICH", "DELN"}
+		res.Root.Data.List[1].Config.Stations = []string{"DUBL", "WDUB"}
+		if _, err := NewNetwork(res).Path("rich", "dubl"); !errors.Is(err, ErrNoPath) {
+			t.Errorf("expected %v, got %v", ErrNoPath, err)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package bart
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// A RateLimiter is a token bucket which limits how often requests are made. It
+// holds up to burst tokens, and refills at a steady rate. Each request takes one
+// token, and waits for one if the bucket is empty. A RateLimiter is safe for
+// concurrent use, so it may be shared by several Clients.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter makes a RateLimiter which allows perSecond requests per second
+// on average, with bursts of up to burst requests. The bucket starts out full.
+// A burst less than 1 is treated as 1.
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available, or until ctx is done, whichever
+// happens first. The error is non-nil only when ctx is done. It's OK to call
+// this method on a nil RateLimiter, which never waits. A RateLimiter with a
+// rate of zero or less also never waits.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.rate <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	// Reserve a token now, even if that means going into debt. Waiting for the
+	// debt to be paid off keeps the callers in order.
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	if err := sleep(ctx, wait); err != nil {
+		// Give back the reservation since it was not used.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// rateLimiter picks the RateLimiter for requests to route.
+func (c *Config) rateLimiter(route string) *RateLimiter {
+	if limiter, ok := c.RouteRateLimits[route]; ok {
+		return limiter
+	}
+	return c.RateLimit
+}
@@ -0,0 +1,81 @@
+package bart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("waits for tokens", func(t *testing.T) {
+		limiter := NewRateLimiter(50, 1)
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// The first token is already in the bucket, the next two each take
+		// 1/50th of a second to refill.
+		if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+			t.Errorf("expected to wait for tokens, only took %v", elapsed)
+		}
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		limiter := NewRateLimiter(0.001, 1)
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var limiter *RateLimiter
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Errorf("unexpected error, %v", err)
+		}
+	})
+
+	t.Run("per route", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"root":{"message":""}}`)
+		}))
+		defer server.Close()
+
+		// Empty the bucket for the etd route, so the next request must wait.
+		etdLimiter := NewRateLimiter(0.001, 1)
+		if err := etdLimiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		client := stubAPI{conf: &Config{
+			HTTP:            &http.Client{},
+			RateLimit:       NewRateLimiter(1000, 10),
+			RouteRateLimits: map[string]*RateLimiter{"/etd.aspx": etdLimiter},
+		}}
+		client.conf.BaseURL = server.URL
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		var out interface{}
+		sched := apiRequest{route: "/sched.aspx", cmd: "foo"}
+		if err := sched.requestAPI(ctx, &client, &out); err != nil {
+			t.Errorf("unexpected error, %v", err)
+		}
+		etd := apiRequest{route: "/etd.aspx", cmd: "etd"}
+		if err := etd.requestAPI(ctx, &client, &out); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package bart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// A RetryPolicy describes when and how to retry a failed request. The same
+// policy applies to requests from every API namespace of a Client. The zero
+// value makes only one attempt; see DefaultRetryPolicy for a reasonable
+// starting point.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles on every
+	// following retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, including delays requested
+	// by a Retry-After header. No cap is applied when it's zero.
+	MaxDelay time.Duration
+	// Jitter is the fraction, from 0 to 1, of each delay that is randomized.
+	Jitter float64
+	// RetryableStatusCodes lists HTTP status codes of responses to retry.
+	RetryableStatusCodes []int
+	// RetryableError reports whether an error from sending the request, or
+	// from reading the response, should be retried. If it's nil, then timeouts,
+	// refused or reset connections and unexpected EOFs are retried.
+	RetryableError func(err error) bool
+	// IgnoreRetryAfter disables the use of the Retry-After response header as
+	// the delay before the next attempt.
+	IgnoreRetryAfter bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy which makes up to 3 attempts, and
+// retries rate limiting and transient server errors.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Jitter:      0.5,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// RetryError is returned when a request has been attempted more than once and
+// every attempt failed, or when ctx was done while waiting to retry. Unwrap
+// returns the error from the last attempt, and Is also matches the Interrupted
+// error.
+type RetryError struct {
+	// Attempts has the error from each attempt, in order.
+	Attempts []error
+	// Interrupted is the error of the context, if it was done while waiting
+	// for the next attempt, either for the retry delay or the rate limit.
+	Interrupted error
+}
+
+func (e *RetryError) Error() string {
+	msg := fmt.Sprintf("request failed after %d attempts: %v", len(e.Attempts), e.Unwrap())
+	if e.Interrupted != nil {
+		msg += fmt.Sprintf("; stopped retrying: %v", e.Interrupted)
+	}
+	return msg
+}
+
+func (e *RetryError) Is(target error) bool {
+	return e.Interrupted != nil && errors.Is(e.Interrupted, target)
+}
+
+func (e *RetryError) Unwrap() error {
+	if len(e.Attempts) < 1 {
+		return nil
+	}
+	return e.Attempts[len(e.Attempts)-1]
+}
+
+// do calls fn until it succeeds, or until the policy says to stop. Each attempt
+// first waits for a token from limiter. It's OK to call this method on a nil
+// policy, which makes exactly one attempt.
+func (r *RetryPolicy) do(ctx context.Context, limiter *RateLimiter, fn func() ([]byte, http.Header, error)) ([]byte, error) {
+	attempts := make([]error, 0, 1)
+	var interrupted error
+	for attempt := 1; ; attempt++ {
+		// Like an interrupted sleep, a ctx which is done while waiting for a
+		// token stops the retries, but it's not the error of an attempt.
+		if interrupted = limiter.Wait(ctx); interrupted != nil {
+			break
+		}
+		raw, header, err := fn()
+		if err == nil {
+			return raw, nil
+		}
+		attempts = append(attempts, err)
+		if !r.shouldRetry(ctx, attempt, err) {
+			break
+		}
+		if interrupted = sleep(ctx, r.delay(attempt, header)); interrupted != nil {
+			break
+		}
+	}
+
+	if len(attempts) == 0 {
+		return nil, interrupted
+	}
+	if len(attempts) == 1 && interrupted == nil {
+		return nil, attempts[0]
+	}
+	return nil, &RetryError{Attempts: attempts, Interrupted: interrupted}
+}
+
+func (r *RetryPolicy) shouldRetry(ctx context.Context, attempt int, err error) bool {
+	if r == nil || attempt >= r.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		for _, code := range r.RetryableStatusCodes {
+			if apiErr.StatusCode == code {
+				return true
+			}
+		}
+		return false
+	}
+
+	if r.RetryableError != nil {
+		return r.RetryableError(err)
+	}
+	return isRetryableNetworkError(err)
+}
+
+func isRetryableNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+// delay calculates how long to wait after the attempt. The Retry-After header,
+// if present in header, takes precedence over exponential backoff.
+func (r *RetryPolicy) delay(attempt int, header http.Header) time.Duration {
+	if !r.IgnoreRetryAfter {
+		if d, ok := parseRetryAfter(header.Get("Retry-After"), time.Now()); ok {
+			return r.capDelay(d)
+		}
+	}
+
+	d := float64(r.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if d > math.MaxInt64 {
+		d = math.MaxInt64
+	}
+	return applyJitter(r.capDelay(time.Duration(d)), r.Jitter)
+}
+
+// applyJitter randomly shortens d by up to the jitter fraction of it.
+func applyJitter(d time.Duration, jitter float64) time.Duration {
+	if jitter = math.Min(math.Max(jitter, 0), 1); jitter > 0 {
+		spread := float64(d) * jitter
+		d = time.Duration(float64(d) - spread + rand.Float64()*spread)
+	}
+	return d
+}
+
+func (r *RetryPolicy) capDelay(d time.Duration) time.Duration {
+	if r.MaxDelay > 0 && d > r.MaxDelay {
+		return r.MaxDelay
+	}
+	return d
+}
+
+// parseRetryAfter reads the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date.
+func parseRetryAfter(val string, now time.Time) (time.Duration, bool) {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(val); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	when, err := http.ParseTime(val)
+	if err != nil {
+		return 0, false
+	}
+	if d := when.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// sleep waits for d to elapse, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,240 @@
+package bart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	// makeServer responds with each of the status codes in order. After that,
+	// it responds OK.
+	makeServer := func(statusCodes ...int) (*httptest.Server, *int) {
+		var calls int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls <= len(statusCodes) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(statusCodes[calls-1])
+				return
+			}
+			fmt.Fprint(w, `{"root":{"message":""}}`)
+		}))
+		return server, &calls
+	}
+
+	policy := &RetryPolicy{
+		MaxAttempts:          3,
+		BaseDelay:            time.Millisecond,
+		MaxDelay:             10 * time.Millisecond,
+		Jitter:               0.5,
+		RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	}
+
+	t.Run("eventually ok", func(t *testing.T) {
+		server, calls := makeServer(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		defer server.Close()
+
+		client := stubAPI{conf: &Config{HTTP: &http.Client{}, Retry: policy}}
+		client.conf.BaseURL = server.URL
+
+		var out interface{}
+		params := apiRequest{route: "/ok", cmd: "foo"}
+		if err := params.requestAPI(context.Background(), &client, &out); err != nil {
+			t.Fatalf("unexpected error, %v", err)
+		}
+		if *calls != 3 {
+			t.Errorf("wrong number of calls; got %d, expected %d", *calls, 3)
+		}
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		server, calls := makeServer(
+			http.StatusServiceUnavailable,
+			http.StatusServiceUnavailable,
+			http.StatusServiceUnavailable,
+		)
+		defer server.Close()
+
+		client := stubAPI{conf: &Config{HTTP: &http.Client{}, Retry: policy}}
+		client.conf.BaseURL = server.URL
+
+		var out interface{}
+		params := apiRequest{route: "/ok", cmd: "foo"}
+		err := params.requestAPI(context.Background(), &client, &out)
+
+		var retryErr *RetryError
+		if !errors.As(err, &retryErr) {
+			t.Fatalf("expected %T, got %v", retryErr, err)
+		}
+		if len(retryErr.Attempts) != 3 {
+			t.Errorf("wrong number of attempts; got %d, expected %d", len(retryErr.Attempts), 3)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected last attempt to be an %T, got %v", apiErr, err)
+		}
+		if *calls != 3 {
+			t.Errorf("wrong number of calls; got %d, expected %d", *calls, 3)
+		}
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		server, calls := makeServer(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var out interface{}
+		params := apiRequest{route: "/ok", cmd: "foo"}
+		slow := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, IgnoreRetryAfter: true, RetryableStatusCodes: policy.RetryableStatusCodes}
+		client := stubAPI{conf: &Config{HTTP: &http.Client{}, Retry: slow}}
+		client.conf.BaseURL = server.URL
+
+		time.AfterFunc(10*time.Millisecond, cancel)
+		err := params.requestAPI(ctx, &client, &out)
+
+		var retryErr *RetryError
+		if !errors.As(err, &retryErr) {
+			t.Fatalf("expected %T, got %v", retryErr, err)
+		}
+		if len(retryErr.Attempts) != 1 {
+			t.Errorf("wrong number of attempts; got %d, expected %d", len(retryErr.Attempts), 1)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected error to match %v, got %v", context.Canceled, err)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected last attempt to be an %T, got %v", apiErr, err)
+		}
+		if *calls != 1 {
+			t.Errorf("wrong number of calls; got %d, expected %d", *calls, 1)
+		}
+	})
+
+	t.Run("interrupted by rate limit", func(t *testing.T) {
+		server, calls := makeServer(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var out interface{}
+		params := apiRequest{route: "/ok", cmd: "foo"}
+		// The first attempt takes the only token, so the retry waits on the
+		// limiter rather than on the delay.
+		client := stubAPI{conf: &Config{
+			HTTP:      &http.Client{},
+			Retry:     policy,
+			RateLimit: NewRateLimiter(1.0/3600, 1),
+		}}
+		client.conf.BaseURL = server.URL
+
+		time.AfterFunc(10*time.Millisecond, cancel)
+		err := params.requestAPI(ctx, &client, &out)
+
+		var retryErr *RetryError
+		if !errors.As(err, &retryErr) {
+			t.Fatalf("expected %T, got %v", retryErr, err)
+		}
+		if len(retryErr.Attempts) != 1 {
+			t.Errorf("wrong number of attempts; got %d, expected %d", len(retryErr.Attempts), 1)
+		}
+		if !errors.Is(retryErr.Interrupted, context.Canceled) {
+			t.Errorf("wrong Interrupted; got %v, expected %v", retryErr.Interrupted, context.Canceled)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected last attempt to be an %T, got %v", apiErr, err)
+		}
+		if *calls != 1 {
+			t.Errorf("wrong number of calls; got %d, expected %d", *calls, 1)
+		}
+
+		// Nothing is attempted when ctx is done before the first token.
+		err = params.requestAPI(ctx, &client, &out)
+		if !errors.Is(err, context.Canceled) || errors.As(err, &retryErr) {
+			t.Errorf("wrong error; got %v, expected %v", err, context.Canceled)
+		}
+		if *calls != 1 {
+			t.Errorf("wrong number of calls; got %d, expected %d", *calls, 1)
+		}
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		server, calls := makeServer(http.StatusBadRequest)
+		defer server.Close()
+
+		client := stubAPI{conf: &Config{HTTP: &http.Client{}, Retry: policy}}
+		client.conf.BaseURL = server.URL
+
+		var out interface{}
+		params := apiRequest{route: "/ok", cmd: "foo"}
+		err := params.requestAPI(context.Background(), &client, &out)
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected %T, got %v", apiErr, err)
+		}
+		if *calls != 1 {
+			t.Errorf("wrong number of calls; got %d, expected %d", *calls, 1)
+		}
+	})
+
+	t.Run("network error", func(t *testing.T) {
+		server, calls := makeServer()
+		defer server.Close()
+
+		var failures int
+		transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if failures < 1 {
+				failures++
+				return nil, timeoutError{}
+			}
+			return http.DefaultTransport.RoundTrip(req)
+		})
+		client := stubAPI{conf: &Config{HTTP: &http.Client{Transport: transport}, Retry: policy}}
+		client.conf.BaseURL = server.URL
+
+		var out interface{}
+		params := apiRequest{route: "/ok", cmd: "foo"}
+		if err := params.requestAPI(context.Background(), &client, &out); err != nil {
+			t.Fatalf("unexpected error, %v", err)
+		}
+		if *calls != 1 {
+			t.Errorf("wrong number of calls; got %d, expected %d", *calls, 1)
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 8, 15, 16, 34, 0, 0, time.UTC)
+	tests := []struct {
+		in            string
+		expectedDelay time.Duration
+		expectedOK    bool
+	}{
+		{in: "", expectedOK: false},
+		{in: "nope", expectedOK: false},
+		{in: "-1", expectedOK: false},
+		{in: "3", expectedDelay: 3 * time.Second, expectedOK: true},
+		{in: now.Add(time.Minute).Format(http.TimeFormat), expectedDelay: time.Minute, expectedOK: true},
+		{in: now.Add(-time.Minute).Format(http.TimeFormat), expectedDelay: 0, expectedOK: true},
+	}
+	for _, test := range tests {
+		delay, ok := parseRetryAfter(test.in, now)
+		if ok != test.expectedOK || delay != test.expectedDelay {
+			t.Errorf("input %q; got (%v, %t), expected (%v, %t)", test.in, delay, ok, test.expectedDelay, test.expectedOK)
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -1,5 +1,11 @@
 package bart
 
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
 func initRoutesRequest(cmd, date string) (out apiRequest) {
 	out.route = "/route.aspx"
 	out.cmd = cmd
@@ -28,10 +34,32 @@ func (a *RoutesAPI) clientConf() *Config {
 // date. Otherwise, format like "mm/dd/yyyy". See official docs at
 // https://api.bart.gov/docs/route/routeinfo.aspx.
 func (a *RoutesAPI) RequestRoutesInfo(date string) (res RoutesInfoResponse, err error) {
-	params := initRoutesRequest("routeinfo", date)
-	params.options["route"] = []string{"all"}
+	return a.RequestRoutesInfoContext(context.Background(), date)
+}
+
+// RequestRoutesInfoContext is like RequestRoutesInfo, but uses ctx for the
+// request.
+func (a *RoutesAPI) RequestRoutesInfoContext(ctx context.Context, date string) (res RoutesInfoResponse, err error) {
+	return a.RequestRouteInfoContext(ctx, RouteParams{Date: date})
+}
 
-	err = params.requestAPI(a, &res)
+// RequestRouteInfo requests detailed information for one route, or for all
+// routes, on the schedule and date specified in the RouteParams. Pass in the
+// zero-value for all routes on the current schedule, today. See that type's
+// documentation for details. See official docs at
+// https://api.bart.gov/docs/route/routeinfo.aspx.
+func (a *RoutesAPI) RequestRouteInfo(p RouteParams) (res RoutesInfoResponse, err error) {
+	return a.RequestRouteInfoContext(context.Background(), p)
+}
+
+// RequestRouteInfoContext is like RequestRouteInfo, but uses ctx for the
+// request.
+func (a *RoutesAPI) RequestRouteInfoContext(ctx context.Context, p RouteParams) (res RoutesInfoResponse, err error) {
+	params, err := p.initRequestParams("routeinfo")
+	if err != nil {
+		return
+	}
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -41,32 +69,94 @@ type RoutesInfoResponse struct {
 		ResponseMetaData
 		SchedNum int `json:"sched_num,string"`
 		Data     struct {
-			List []struct {
-				Name        string
-				Abbr        string
-				RouteID     string
-				Number      int `json:",string"`
-				Origin      string
-				Destination string
-				Direction   string // 'North' or 'South'
-				Hexcolor    string
-				Color       string
-				Holidays    int `json:",string"`
-				NumStations int `json:"num_stations,string"`
-				Config      struct {
-					Stations []string `json:"station"`
-				}
-			} `json:"route"`
+			List []RouteInfo `json:"route"`
 		} `json:"Routes"`
 	}
 }
 
+func (r *RoutesInfoResponse) UnmarshalJSON(in []byte) (err error) {
+	type routesInfoResponseJSON RoutesInfoResponse
+	var s routesInfoResponseJSON
+
+	err = json.Unmarshal(in, &s)
+	switch err.(type) {
+	case nil:
+		*r = RoutesInfoResponse(s)
+		return
+	case *json.UnmarshalTypeError:
+		// This is *probably* the case where one route is requested, so the
+		// route is an object rather than a list of them.
+		var t struct {
+			Root struct {
+				ResponseMetaData
+				SchedNum int `json:"sched_num,string"`
+				Data     struct {
+					Route RouteInfo `json:"route"`
+				} `json:"Routes"`
+			}
+		}
+		if json.Unmarshal(in, &t) != nil {
+			return
+		}
+		var out RoutesInfoResponse
+		out.Root.ResponseMetaData = t.Root.ResponseMetaData
+		out.Root.SchedNum = t.Root.SchedNum
+		out.Root.Data.List = []RouteInfo{t.Root.Data.Route}
+		*r = out
+		err = nil
+		return
+	default:
+		return
+	}
+}
+
+// RouteInfo is the details of one route, including its stations in order.
+type RouteInfo struct {
+	Name        string
+	Abbr        string
+	RouteID     string
+	Number      int `json:",string"`
+	Origin      string
+	Destination string
+	Direction   string // 'North' or 'South'
+	Hexcolor    string
+	Color       string
+	Holidays    int `json:",string"`
+	NumStations int `json:"num_stations,string"`
+	Config      struct {
+		Stations []string `json:"station"`
+	}
+}
+
 // RequestRoutes requests (less) detailed information on current routes. If you
 // only want current schedule on current date, just pass empty strings for date.
 // See official docs at https://api.bart.gov/docs/route/routes.aspx.
 func (a *RoutesAPI) RequestRoutes(date string) (res RoutesResponse, err error) {
-	params := initRoutesRequest("routes", date)
-	err = params.requestAPI(a, &res)
+	return a.RequestRoutesContext(context.Background(), date)
+}
+
+// RequestRoutesContext is like RequestRoutes, but uses ctx for the request.
+func (a *RoutesAPI) RequestRoutesContext(ctx context.Context, date string) (res RoutesResponse, err error) {
+	return a.RequestRoutesWithParamsContext(ctx, RouteParams{Date: date})
+}
+
+// RequestRoutesWithParams is just like the RequestRoutes method except it takes
+// a RouteParams value, which can also pick a schedule. The Route field of the
+// RouteParams is not used. See official docs at
+// https://api.bart.gov/docs/route/routes.aspx.
+func (a *RoutesAPI) RequestRoutesWithParams(p RouteParams) (res RoutesResponse, err error) {
+	return a.RequestRoutesWithParamsContext(context.Background(), p)
+}
+
+// RequestRoutesWithParamsContext is like RequestRoutesWithParams, but uses ctx
+// for the request.
+func (a *RoutesAPI) RequestRoutesWithParamsContext(ctx context.Context, p RouteParams) (res RoutesResponse, err error) {
+	p.Route = 0
+	params, err := p.initRequestParams("routes")
+	if err != nil {
+		return
+	}
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -76,14 +166,46 @@ type RoutesResponse struct {
 		ResponseMetaData
 		SchedNum int `json:"sched_num,string"`
 		Data     struct {
-			List []struct {
-				Name     string
-				Abbr     string
-				RouteID  string
-				Number   int `json:",string"`
-				Hexcolor string
-				Color    string
-			} `json:"Route"`
+			List []RouteSummary `json:"Route"`
 		} `json:"Routes"`
 	}
 }
+
+// RouteSummary is the name, number and color of one route.
+type RouteSummary struct {
+	Name     string
+	Abbr     string
+	RouteID  string
+	Number   int `json:",string"`
+	Hexcolor string
+	Color    string
+}
+
+// RouteParams is a helper for two methods: RequestRouteInfo,
+// RequestRoutesWithParams. Every field is optional. Route is a route number,
+// such as 1-8, 11-12 or 19-20, and the zero-value means all routes. Sched is a
+// schedule number, which you can get from RequestAvailableSchedules, and the
+// zero-value means the current schedule. Date is formatted like "mm/dd/yyyy",
+// and the zero-value means today.
+type RouteParams struct {
+	Route int
+	Sched int
+	Date  string
+}
+
+func (p RouteParams) initRequestParams(cmd string) (out apiRequest, err error) {
+	if p.Route < 0 {
+		err = &InvalidRouteError{Route: p.Route}
+		return
+	}
+	out = initRoutesRequest(cmd, p.Date)
+	if cmd == "routeinfo" {
+		route := "all"
+		if p.Route > 0 {
+			route = strconv.Itoa(p.Route)
+		}
+		out.options["route"] = []string{route}
+	}
+	err = out.setSched(p.Sched)
+	return
+}
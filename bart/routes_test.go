@@ -0,0 +1,81 @@
+package bart
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestRouteParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      string
+		params   RouteParams
+		expected url.Values
+	}{
+		{
+			name:     "all routes",
+			cmd:      "routeinfo",
+			params:   RouteParams{},
+			expected: url.Values{"route": {"all"}},
+		},
+		{
+			name:     "one route",
+			cmd:      "routeinfo",
+			params:   RouteParams{Route: 12, Sched: 72, Date: "01/11/2027"},
+			expected: url.Values{"route": {"12"}, "sched": {"72"}, "date": {"01/11/2027"}},
+		},
+		{
+			name:     "summaries",
+			cmd:      "routes",
+			params:   RouteParams{Sched: 72},
+			expected: url.Values{"sched": {"72"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.params.initRequestParams(test.cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.route != "/route.aspx" {
+				t.Errorf("wrong route; got %q, expected %q", got.route, "/route.aspx")
+			}
+			if len(got.options) != len(test.expected) {
+				t.Errorf("wrong number of options; got %v, expected %v", got.options, test.expected)
+			}
+			for key := range test.expected {
+				if val := url.Values(got.options).Get(key); val != test.expected.Get(key) {
+					t.Errorf("wrong %s; got %q, expected %q", key, val, test.expected.Get(key))
+				}
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := (RouteParams{Route: -1}).initRequestParams("routeinfo")
+		if !errors.Is(err, ErrInvalidRoute) {
+			t.Errorf("expected %v, got %v", ErrInvalidRoute, err)
+		}
+		var routeErr *InvalidRouteError
+		if !errors.As(err, &routeErr) || routeErr.Route != -1 {
+			t.Errorf("expected %T for route -1, got %v", routeErr, err)
+		}
+
+		_, err = (RouteParams{Sched: -1}).initRequestParams("routes")
+		if !errors.Is(err, ErrInvalidSched) {
+			t.Errorf("expected %v, got %v", ErrInvalidSched, err)
+		}
+		var schedErr *InvalidSchedError
+		if !errors.As(err, &schedErr) || schedErr.Sched != -1 {
+			t.Errorf("expected %T for sched -1, got %v", schedErr, err)
+		}
+
+		// These errors are not from the BART API.
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			t.Errorf("did not expect %T, got %v", apiErr, err)
+		}
+	})
+}
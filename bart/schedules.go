@@ -1,6 +1,7 @@
 package bart
 
 import (
+	"context"
 	"encoding/json"
 	"strconv"
 )
@@ -30,8 +31,16 @@ func (a *SchedulesAPI) clientConf() *Config {
 // for details on requesting an arrival. See official docs at
 // https://api.bart.gov/docs/sched/arrive.aspx.
 func (a *SchedulesAPI) RequestArrivals(p TripParams) (res TripsResponse, err error) {
-	params := p.initRequestParams("arrive")
-	err = params.requestAPI(a, &res)
+	return a.RequestArrivalsContext(context.Background(), p)
+}
+
+// RequestArrivalsContext is like RequestArrivals, but uses ctx for the request.
+func (a *SchedulesAPI) RequestArrivalsContext(ctx context.Context, p TripParams) (res TripsResponse, err error) {
+	params, err := p.initRequestParams("arrive")
+	if err != nil {
+		return
+	}
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -40,8 +49,17 @@ func (a *SchedulesAPI) RequestArrivals(p TripParams) (res TripsResponse, err err
 // documentation for details on requesting a departure. See official docs at
 // https://api.bart.gov/docs/sched/depart.aspx.
 func (a *SchedulesAPI) RequestDepartures(p TripParams) (res TripsResponse, err error) {
-	params := p.initRequestParams("depart")
-	err = params.requestAPI(a, &res)
+	return a.RequestDeparturesContext(context.Background(), p)
+}
+
+// RequestDeparturesContext is like RequestDepartures, but uses ctx for the
+// request.
+func (a *SchedulesAPI) RequestDeparturesContext(ctx context.Context, p TripParams) (res TripsResponse, err error) {
+	params, err := p.initRequestParams("depart")
+	if err != nil {
+		return
+	}
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -59,25 +77,34 @@ type TripsResponse struct {
 			Before  int `json:",string"`
 			After   int `json:",string"`
 			Request struct {
-				List []struct {
-					OrigDestTimeData
-					TripTime int `json:"@tripTime,string"`
-					Legs     []struct {
-						OrigDestTimeData
-						Order            int    `json:"@order,string"`
-						Line             string `json:"@line"`
-						BikeFlag         Bool   `json:"@bikeflag,string"`
-						TrainHeadStation string `json:"@trainHeadStation"`
-						Load             int    `json:"@load,string"`
-					} `json:"leg"`
-				} `json:"Trip"`
+				List []Trip `json:"Trip"`
 			}
 		} `json:"schedule"`
 	}
 }
 
+// Trip is one way to get from the origin to the destination, in a response to
+// RequestArrivals or RequestDepartures. It has a TripLeg for each train.
+type Trip struct {
+	OrigDestTimeData
+	TripTime int       `json:"@tripTime,string"`
+	Legs     []TripLeg `json:"leg"`
+}
+
+// TripLeg is a ride on one train, as part of a Trip.
+type TripLeg struct {
+	OrigDestTimeData
+	Order            int    `json:"@order,string"`
+	Line             string `json:"@line"`
+	BikeFlag         Bool   `json:"@bikeflag,string"`
+	TrainHeadStation string `json:"@trainHeadStation"`
+	Load             int    `json:"@load,string"`
+}
+
 // OrigDestTimeData is an internal helper container, only meant to DRY up some
-// type definitions.
+// type definitions. The OrigTimeMin, DestTimeMin fields are times of day, such
+// as "10:15 AM", and the OrigTimeDate, DestTimeDate fields are service dates.
+// Use the OrigTime, DestTime methods to combine them.
 type OrigDestTimeData struct {
 	Origin       string `json:"@origin"`
 	Destination  string `json:"@destination"`
@@ -87,12 +114,151 @@ type OrigDestTimeData struct {
 	DestTimeDate string `json:"@destTimeDate"`
 }
 
+// RequestFare requests the fares for a trip between two stations. The orig and
+// dest params must be a 4-letter abbreviation for a station name. The date is
+// formatted like "mm/dd/yyyy", or pass an empty string for today. See official
+// docs at https://api.bart.gov/docs/sched/fare.aspx.
+func (a *SchedulesAPI) RequestFare(orig, dest, date string) (res FareResponse, err error) {
+	return a.RequestFareContext(context.Background(), orig, dest, date)
+}
+
+// RequestFareContext is like RequestFare, but uses ctx for the request.
+func (a *SchedulesAPI) RequestFareContext(ctx context.Context, orig, dest, date string) (res FareResponse, err error) {
+	return a.RequestFareWithParamsContext(ctx, FareParams{Orig: orig, Dest: dest, Date: date})
+}
+
+// RequestFareWithParams is just like the RequestFare method except it takes a
+// FareParams value, which can also pick a schedule. See official docs at
+// https://api.bart.gov/docs/sched/fare.aspx.
+func (a *SchedulesAPI) RequestFareWithParams(p FareParams) (res FareResponse, err error) {
+	return a.RequestFareWithParamsContext(context.Background(), p)
+}
+
+// RequestFareWithParamsContext is like RequestFareWithParams, but uses ctx for
+// the request.
+func (a *SchedulesAPI) RequestFareWithParamsContext(ctx context.Context, p FareParams) (res FareResponse, err error) {
+	orig, err := normalizeStation("orig", p.Orig)
+	if err != nil {
+		return
+	}
+	dest, err := normalizeStation("dest", p.Dest)
+	if err != nil {
+		return
+	}
+	params := initSchedulesRequest("fare")
+	params.options["orig"] = []string{orig}
+	params.options["dest"] = []string{dest}
+
+	if p.Date != "" {
+		params.options["date"] = []string{p.Date}
+	}
+	if err = params.setSched(p.Sched); err != nil {
+		return
+	}
+
+	err = params.requestAPI(ctx, a, &res)
+	return
+}
+
+// FareParams is a helper for the RequestFareWithParams method. The Orig and
+// Dest fields are required and must be a 4-letter abbreviation for a station
+// name. The Date field is formatted like "mm/dd/yyyy", and the zero-value means
+// today. The Sched field is a schedule number, which you can get from
+// RequestAvailableSchedules, and the zero-value means the current schedule.
+type FareParams struct {
+	Orig  string
+	Dest  string
+	Date  string
+	Sched int
+}
+
+// FareResponse is the shape of an API response. There is a Fare for each
+// FareClass. Use the methods, such as Regular or Clipper, to get the amount for
+// one of them.
+type FareResponse struct {
+	Root struct {
+		ResponseMetaData
+		Origin      string
+		Destination string
+		SchedNum    int `json:"sched_num,string"`
+		Data        struct {
+			Level string `json:"@level"`
+			List  []Fare `json:"fare"`
+		} `json:"fares"`
+	}
+}
+
+// Fare is the price of a trip for one class of rider, in dollars.
+type Fare struct {
+	Amount float64   `json:"@amount,string"`
+	Class  FareClass `json:"@class"`
+	Name   string    `json:"@name"`
+}
+
+// FareClass is a kind of fare, by rider and payment method.
+type FareClass string
+
+const (
+	// FareClassCash is the regular fare, paid with a paper ticket.
+	FareClassCash FareClass = "cash"
+	// FareClassClipper is the regular fare, paid with a Clipper card.
+	FareClassClipper FareClass = "clipper"
+	// FareClassSeniorDisabled is the fare for seniors and people with
+	// disabilities, paid with a Clipper card.
+	FareClassSeniorDisabled FareClass = "rtcclipper"
+	// FareClassYouth is the fare for youth, paid with a Clipper card.
+	FareClassYouth FareClass = "student"
+	// FareClassDiscounted is the fare for riders in the Clipper START
+	// program, for adults with low incomes.
+	FareClassDiscounted FareClass = "clipperstart"
+)
+
+// Fare looks up the amount for a class of fare. The second output is false if
+// the response doesn't list that class.
+func (r FareResponse) Fare(class FareClass) (float64, bool) {
+	for _, fare := range r.Root.Data.List {
+		if fare.Class == class {
+			return fare.Amount, true
+		}
+	}
+	return 0, false
+}
+
+// Regular is the amount of the regular fare, paid with a paper ticket. It's 0
+// if the response doesn't list it, and likewise for the other methods.
+func (r FareResponse) Regular() float64 { return r.amount(FareClassCash) }
+
+// Clipper is the amount of the regular fare, paid with a Clipper card.
+func (r FareResponse) Clipper() float64 { return r.amount(FareClassClipper) }
+
+// SeniorDisabled is the amount of the fare for seniors and people with
+// disabilities.
+func (r FareResponse) SeniorDisabled() float64 { return r.amount(FareClassSeniorDisabled) }
+
+// Youth is the amount of the fare for youth.
+func (r FareResponse) Youth() float64 { return r.amount(FareClassYouth) }
+
+// Discounted is the amount of the fare for riders in the Clipper START
+// program.
+func (r FareResponse) Discounted() float64 { return r.amount(FareClassDiscounted) }
+
+func (r FareResponse) amount(class FareClass) float64 {
+	out, _ := r.Fare(class)
+	return out
+}
+
 // RequestHolidaySchedules requests information on the upcoming BART holidays,
 // and what type of schedule will be run on those days.
 // https://api.bart.gov/docs/sched/holiday.aspx.
 func (a *SchedulesAPI) RequestHolidaySchedules() (res HolidaySchedulesResponse, err error) {
+	return a.RequestHolidaySchedulesContext(context.Background())
+}
+
+// RequestHolidaySchedulesContext is like RequestHolidaySchedules, but uses ctx
+// for the request.
+func (a *SchedulesAPI) RequestHolidaySchedulesContext(ctx context.Context) (res HolidaySchedulesResponse, err error) {
 	params := initSchedulesRequest("holiday")
-	err = params.requestAPI(a, &res)
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -101,20 +267,30 @@ type HolidaySchedulesResponse struct {
 	Root struct {
 		ResponseMetaData
 		Data []struct {
-			List []struct {
-				Name         string
-				Date         string
-				ScheduleType string `json:"schedule_type"`
-			} `json:"holiday"`
+			List []Holiday `json:"holiday"`
 		} `json:"holidays"`
 	}
 }
 
+// Holiday is a day when BART runs another day's schedule. The ScheduleType
+// field is "Saturday" or "Sunday".
+type Holiday struct {
+	Name         string
+	Date         string
+	ScheduleType string `json:"schedule_type"`
+}
+
 // RequestAvailableSchedules requests information about the currently available
 // schedules. See official docs at https://api.bart.gov/docs/sched/scheds.aspx.
 func (a *SchedulesAPI) RequestAvailableSchedules() (res AvailableSchedulesResponse, err error) {
+	return a.RequestAvailableSchedulesContext(context.Background())
+}
+
+// RequestAvailableSchedulesContext is like RequestAvailableSchedules, but uses
+// ctx for the request.
+func (a *SchedulesAPI) RequestAvailableSchedulesContext(ctx context.Context) (res AvailableSchedulesResponse, err error) {
 	params := initSchedulesRequest("scheds")
-	err = params.requestAPI(a, &res)
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -123,20 +299,29 @@ type AvailableSchedulesResponse struct {
 	Root struct {
 		ResponseMetaData
 		Data struct {
-			List []struct {
-				ID            int    `json:"@id,string"`
-				EffectiveDate string `json:"@effectivedate"`
-			} `json:"schedule"`
+			List []Schedule `json:"schedule"`
 		} `json:"schedules"`
 	}
 }
 
+// Schedule is an edition of the BART schedule, and the date it takes effect.
+type Schedule struct {
+	ID            int    `json:"@id,string"`
+	EffectiveDate string `json:"@effectivedate"`
+}
+
 // RequestSpecialSchedules requests information about all special schedule
 // notices in effect. See official docs at
 // https://api.bart.gov/docs/sched/special.aspx.
 func (a *SchedulesAPI) RequestSpecialSchedules() (res SpecialSchedulesResponse, err error) {
+	return a.RequestSpecialSchedulesContext(context.Background())
+}
+
+// RequestSpecialSchedulesContext is like RequestSpecialSchedules, but uses ctx
+// for the request.
+func (a *SchedulesAPI) RequestSpecialSchedulesContext(ctx context.Context) (res SpecialSchedulesResponse, err error) {
 	params := initSchedulesRequest("special")
-	err = params.requestAPI(a, &res)
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -145,22 +330,25 @@ type SpecialSchedulesResponse struct {
 	Root struct {
 		ResponseMetaData
 		Data struct {
-			List []struct {
-				StartDate      string `json:"start_date"`
-				EndDate        string `json:"end_date"`
-				StartTime      string `json:"start_time"`
-				EndTime        string `json:"end_time"`
-				Text           CDATASection
-				Link           CDATASection
-				Orig           string
-				Dest           string
-				DayOfWeek      string `json:"day_of_week"`
-				RoutesAffected string `json:"routes_affected"`
-			} `json:"special_schedule"`
+			List []SpecialSchedule `json:"special_schedule"`
 		} `json:"special_schedules"`
 	}
 }
 
+// SpecialSchedule is a notice about a change to regular service.
+type SpecialSchedule struct {
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+	StartTime      string `json:"start_time"`
+	EndTime        string `json:"end_time"`
+	Text           CDATASection
+	Link           CDATASection
+	Orig           string
+	Dest           string
+	DayOfWeek      string `json:"day_of_week"`
+	RoutesAffected string `json:"routes_affected"`
+}
+
 func (r *SpecialSchedulesResponse) UnmarshalJSON(in []byte) (err error) {
 	type specialSchedulesResponseJSON SpecialSchedulesResponse
 	var s specialSchedulesResponseJSON
@@ -189,6 +377,15 @@ func (r *SpecialSchedulesResponse) UnmarshalJSON(in []byte) (err error) {
 // formatted as "mm/dd/yyyy". Otherwise you can pass in "" to get today's
 // schedule. See official docs at https://api.bart.gov/docs/sched/stnsched.aspx.
 func (a *SchedulesAPI) RequestStationSchedules(orig, date string) (res StationSchedulesResponse, err error) {
+	return a.RequestStationSchedulesContext(context.Background(), orig, date)
+}
+
+// RequestStationSchedulesContext is like RequestStationSchedules, but uses ctx
+// for the request.
+func (a *SchedulesAPI) RequestStationSchedulesContext(ctx context.Context, orig, date string) (res StationSchedulesResponse, err error) {
+	if orig, err = normalizeStation("orig", orig); err != nil {
+		return
+	}
 	params := initSchedulesRequest("stnsched")
 	params.options["orig"] = []string{orig}
 
@@ -196,7 +393,7 @@ func (a *SchedulesAPI) RequestStationSchedules(orig, date string) (res StationSc
 		params.options["date"] = []string{date}
 	}
 
-	err = params.requestAPI(a, &res)
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -204,24 +401,31 @@ func (a *SchedulesAPI) RequestStationSchedules(orig, date string) (res StationSc
 type StationSchedulesResponse struct {
 	Root struct {
 		ResponseMetaData
+		// Date is the service date of the schedule, formatted as "mm/dd/yyyy".
+		Date     string
 		SchedNum int `json:"sched_num,string"`
 		Data     struct {
 			Name string
 			Abbr string
-			List []struct {
-				Line             string `json:"@line"`
-				TrainHeadStation string `json:"@trainHeadStation"`
-				OrigTime         string `json:"@origTime"`
-				DestTime         string `json:"@destTime"`
-				TrainIdx         int    `json:"@trainIdx,string"`
-				BikeFlag         Bool   `json:"@bikeflag,string"`
-				TrainID          string `json:"@trainId"`
-				Load             int    `json:"@load,string"`
-			} `json:"item"`
+			List []StationScheduleItem `json:"item"`
 		} `json:"station"`
 	}
 }
 
+// StationScheduleItem is a train stopping at a station, in a response to
+// RequestStationSchedules. The OrigTime, DestTime fields are times of day, such
+// as "10:15 AM". Use the OrigTimeOn, DestTimeOn methods to get the full time.
+type StationScheduleItem struct {
+	Line             string `json:"@line"`
+	TrainHeadStation string `json:"@trainHeadStation"`
+	OrigTime         string `json:"@origTime"`
+	DestTime         string `json:"@destTime"`
+	TrainIdx         int    `json:"@trainIdx,string"`
+	BikeFlag         Bool   `json:"@bikeflag,string"`
+	TrainID          string `json:"@trainId"`
+	Load             int    `json:"@load,string"`
+}
+
 // RequestRouteSchedules requests a full schedule for the specified route.
 // Values for the route param must be one of 1-8, 11-12 or 19-20. Other inputs
 // to this method default to the current values for current schedule today. To
@@ -229,43 +433,96 @@ type StationSchedulesResponse struct {
 // edition of the schedule pass in non-zero values as needed. See official docs
 // at https://api.bart.gov/docs/sched/routesched.aspx.
 func (a *SchedulesAPI) RequestRouteSchedules(route int, date string, time string, legend bool) (res RouteSchedulesResponse, err error) {
+	return a.RequestRouteSchedulesContext(context.Background(), route, date, time, legend)
+}
+
+// RequestRouteSchedulesContext is like RequestRouteSchedules, but uses ctx for
+// the request.
+func (a *SchedulesAPI) RequestRouteSchedulesContext(ctx context.Context, route int, date string, time string, legend bool) (res RouteSchedulesResponse, err error) {
+	p := RouteScheduleParams{Route: route, Date: date, Time: time, Legend: legend}
+	return a.RequestRouteSchedulesWithParamsContext(ctx, p)
+}
+
+// RequestRouteSchedulesWithParams is just like the RequestRouteSchedules method
+// except it takes a RouteScheduleParams value, which can also pick a schedule.
+// See official docs at https://api.bart.gov/docs/sched/routesched.aspx.
+func (a *SchedulesAPI) RequestRouteSchedulesWithParams(p RouteScheduleParams) (res RouteSchedulesResponse, err error) {
+	return a.RequestRouteSchedulesWithParamsContext(context.Background(), p)
+}
+
+// RequestRouteSchedulesWithParamsContext is like
+// RequestRouteSchedulesWithParams, but uses ctx for the request.
+func (a *SchedulesAPI) RequestRouteSchedulesWithParamsContext(ctx context.Context, p RouteScheduleParams) (res RouteSchedulesResponse, err error) {
+	if p.Route < 1 {
+		err = &InvalidRouteError{Route: p.Route}
+		return
+	}
 	params := initSchedulesRequest("routesched")
-	params.options["route"] = []string{strconv.Itoa(route)}
-	if date != "" {
-		params.options["date"] = []string{date}
+	params.options["route"] = []string{strconv.Itoa(p.Route)}
+	if p.Date != "" {
+		params.options["date"] = []string{p.Date}
 	}
-	if time != "" {
-		params.options["time"] = []string{time}
+	if p.Time != "" {
+		params.options["time"] = []string{p.Time}
 	}
-	if legend {
+	if p.Legend {
 		params.options["l"] = []string{"1"}
 	}
+	if err = params.setSched(p.Sched); err != nil {
+		return
+	}
 
-	err = params.requestAPI(a, &res)
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
+// RouteScheduleParams is a helper for the RequestRouteSchedulesWithParams
+// method. Values for the Route field must be one of 1-8, 11-12 or 19-20. The
+// other fields default to the current values for the current schedule today. To
+// request specific details, such as the schedule on a certain day or another
+// edition of the schedule, pass in non-zero values as needed. The Sched field
+// is a schedule number, which you can get from RequestAvailableSchedules.
+type RouteScheduleParams struct {
+	Route  int
+	Date   string
+	Time   string
+	Legend bool
+	Sched  int
+}
+
 // RouteSchedulesResponse is the shape of an API response.
 type RouteSchedulesResponse struct {
 	Root struct {
 		ResponseMetaData
+		// Date is the service date of the schedule, formatted as "mm/dd/yyyy".
+		Date     string
 		SchedNum int `json:"sched_num,string"`
 		Data     struct {
-			List []struct {
-				TrainID  string `json:"@trainId"`
-				TrainIdx int    `json:"@trainIdx,string"`
-				Index    int    `json:"@index,string"`
-				Stops    []struct {
-					Station  string `json:"@station"`
-					Load     string `json:"@load"`
-					Level    string `json:"@level"`
-					BikeFlag Bool   `json:"@bikeflag,string"`
-				} `json:"stop"`
-			} `json:"train"`
+			List []ScheduledTrain `json:"train"`
 		} `json:"route"`
 	}
 }
 
+// ScheduledTrain is one train running on a route, in a response to
+// RequestRouteSchedules.
+type ScheduledTrain struct {
+	TrainID  string          `json:"@trainId"`
+	TrainIdx int             `json:"@trainIdx,string"`
+	Index    int             `json:"@index,string"`
+	Stops    []ScheduledStop `json:"stop"`
+}
+
+// ScheduledStop is a ScheduledTrain stopping at a station. The OrigTime field
+// is a time of day, such as "10:15 AM", and it's empty when the train passes
+// the station without stopping. Use the OrigTimeOn method to get the full time.
+type ScheduledStop struct {
+	Station  string `json:"@station"`
+	OrigTime string `json:"@origTime"`
+	Load     string `json:"@load"`
+	Level    string `json:"@level"`
+	BikeFlag Bool   `json:"@bikeflag,string"`
+}
+
 // TripParams is a helper for two methods: RequestArrivals, RequestDepartures.
 // The Orig and Dest fields are required and must be a 4-letter abbreviation for
 // a station name. Passing in zero-values for both Before, After params is not
@@ -283,10 +540,19 @@ type TripParams struct {
 	Legend bool
 }
 
-func (p *TripParams) initRequestParams(cmd string) (out apiRequest) {
+func (p *TripParams) initRequestParams(cmd string) (out apiRequest, err error) {
+	orig, err := normalizeStation("orig", p.Orig)
+	if err != nil {
+		return
+	}
+	dest, err := normalizeStation("dest", p.Dest)
+	if err != nil {
+		return
+	}
+
 	out = initSchedulesRequest(cmd)
-	out.options["orig"] = []string{p.Orig}
-	out.options["dest"] = []string{p.Dest}
+	out.options["orig"] = []string{orig}
+	out.options["dest"] = []string{dest}
 
 	if p.Time != "" {
 		out.options["time"] = []string{p.Time}
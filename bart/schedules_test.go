@@ -1,6 +1,13 @@
 package bart
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
 
 func TestTrips(t *testing.T) {
 	runTest := func(t *testing.T, res TripsResponse, err error) {
@@ -42,7 +49,7 @@ func TestTrips(t *testing.T) {
 		)
 
 		client := NewClient(nil)
-		client.conf.baseURL = server.URL
+		client.conf.BaseURL = server.URL
 
 		params = TripParams{Orig: "woak", Dest: "embr"}
 		res, err = client.RequestArrivals(params)
@@ -72,7 +79,7 @@ func TestTrips(t *testing.T) {
 		)
 
 		client := NewClient(nil)
-		client.conf.baseURL = server.URL
+		client.conf.BaseURL = server.URL
 
 		params = TripParams{Orig: "woak", Dest: "embr"}
 		res, err = client.RequestDepartures(params)
@@ -98,7 +105,7 @@ func TestSpecialSchedules(t *testing.T) {
 		defer server.Close()
 
 		client := NewClient(nil)
-		client.conf.baseURL = server.URL
+		client.conf.BaseURL = server.URL
 
 		_, err := client.RequestSpecialSchedules()
 		if err != nil {
@@ -117,7 +124,7 @@ func TestSpecialSchedules(t *testing.T) {
 		defer server.Close()
 
 		client := NewClient(nil)
-		client.conf.baseURL = server.URL
+		client.conf.BaseURL = server.URL
 
 		out, err := client.RequestSpecialSchedules()
 		if err != nil {
@@ -142,3 +149,139 @@ func TestSpecialSchedules(t *testing.T) {
 		}
 	})
 }
+
+func TestFare(t *testing.T) {
+	server := makeTestServer(t, stubHandler{
+		expectedPath:     "/sched.aspx",
+		expectedCmd:      "fare",
+		responseFilename: "testdata/schedules/fare_ok.json",
+	})
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.conf.BaseURL = server.URL
+
+	res, err := client.RequestFare("12th", "embr", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		got      float64
+		expected float64
+	}{
+		{name: "Regular", got: res.Regular(), expected: 2.35},
+		{name: "Clipper", got: res.Clipper(), expected: 2.35},
+		{name: "SeniorDisabled", got: res.SeniorDisabled(), expected: 0.85},
+		{name: "Youth", got: res.Youth(), expected: 1.15},
+		{name: "Discounted", got: res.Discounted(), expected: 1.15},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.got != test.expected {
+				t.Errorf("wrong fare; got %.2f, expected %.2f", test.got, test.expected)
+			}
+		})
+	}
+
+	t.Run("missing class", func(t *testing.T) {
+		if _, ok := res.Fare(FareClass("bogus")); ok {
+			t.Error("expected no fare for unknown class")
+		}
+	})
+
+	t.Run("invalid station", func(t *testing.T) {
+		_, err := client.RequestFare("12th", "nope", "")
+		if !errors.Is(err, ErrInvalidDest) {
+			t.Errorf("expected %v, got %v", ErrInvalidDest, err)
+		}
+	})
+}
+
+func TestRouteSchedulesRoute(t *testing.T) {
+	var requested bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		fmt.Fprint(w, `{"root":{"message":""}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.conf.BaseURL = server.URL
+
+	for _, route := range []int{0, -1} {
+		_, err := client.RequestRouteSchedulesWithParams(RouteScheduleParams{Route: route})
+		var routeErr *InvalidRouteError
+		if !errors.As(err, &routeErr) || routeErr.Route != route {
+			t.Errorf("expected %T for route %d, got %v", routeErr, route, err)
+		}
+		if !errors.Is(err, ErrInvalidRoute) {
+			t.Errorf("expected %v, got %v", ErrInvalidRoute, err)
+		}
+	}
+	if requested {
+		t.Error("expected no request for invalid route")
+	}
+}
+
+func TestSchedParam(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		fmt.Fprint(w, `{"root":{"message":""}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.conf.BaseURL = server.URL
+
+	tests := []struct {
+		name    string
+		request func(sched int) error
+	}{
+		{
+			name: "fare",
+			request: func(sched int) error {
+				_, err := client.RequestFareWithParams(FareParams{Orig: "12th", Dest: "embr", Sched: sched})
+				return err
+			},
+		},
+		{
+			name: "routesched",
+			request: func(sched int) error {
+				_, err := client.RequestRouteSchedulesWithParams(RouteScheduleParams{Route: 11, Sched: sched})
+				return err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.request(72); err != nil {
+				t.Fatal(err)
+			}
+			if query.Get("cmd") != test.name {
+				t.Errorf("wrong cmd; got %q, expected %q", query.Get("cmd"), test.name)
+			}
+			if query.Get("sched") != "72" {
+				t.Errorf("wrong sched; got %q, expected %q", query.Get("sched"), "72")
+			}
+
+			if err := test.request(0); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := query["sched"]; ok {
+				t.Errorf("expected no sched for current schedule, got %q", query.Get("sched"))
+			}
+
+			query = nil
+			if err := test.request(-1); !errors.Is(err, ErrInvalidSched) {
+				t.Errorf("expected %v, got %v", ErrInvalidSched, err)
+			}
+			if query != nil {
+				t.Error("expected no request for invalid sched")
+			}
+		})
+	}
+}
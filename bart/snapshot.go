@@ -0,0 +1,19 @@
+package bart
+
+import (
+	"embed"
+	"path"
+)
+
+// snapshots are responses bundled with this package, so that some things are
+// available without making a request.
+//
+//go:embed data/*.json
+var snapshots embed.FS
+
+// Snapshot returns a response body bundled with this package, for the cmd
+// "routeinfo" or "stns". DefaultNetwork and DefaultStationIndex are built from
+// them. There is an error for any other cmd.
+func Snapshot(cmd string) ([]byte, error) {
+	return snapshots.ReadFile(path.Join("data", cmd+".json"))
+}
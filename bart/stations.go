@@ -1,5 +1,7 @@
 package bart
 
+import "context"
+
 func initStationsRequest(cmd, orig string) (out apiRequest) {
 	out.route = "/stn.aspx"
 	out.cmd = cmd
@@ -28,8 +30,17 @@ func (a *StationsAPI) clientConf() *Config {
 // station. Pass in a 4-letter abbreviation for a station as the orig param. See
 // official docs at https://api.bart.gov/docs/stn/stnaccess.aspx.
 func (a *StationsAPI) RequestStationAccess(orig string) (res StationAccessResponse, err error) {
+	return a.RequestStationAccessContext(context.Background(), orig)
+}
+
+// RequestStationAccessContext is like RequestStationAccess, but uses ctx for
+// the request.
+func (a *StationsAPI) RequestStationAccessContext(ctx context.Context, orig string) (res StationAccessResponse, err error) {
+	if orig, err = normalizeStation("orig", orig); err != nil {
+		return
+	}
 	params := initStationsRequest("stnaccess", orig)
-	err = params.requestAPI(a, &res)
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -38,32 +49,44 @@ type StationAccessResponse struct {
 	Root struct {
 		ResponseMetaData
 		Data struct {
-			StationAccess struct {
-				Name            string
-				Abbr            string
-				Entering        CDATASection
-				Exiting         CDATASection
-				FillTime        CDATASection
-				CarShare        CDATASection
-				Lockers         CDATASection
-				BikeStationText CDATASection
-				Destinations    CDATASection
-				Link            string
-				ParkingFlag     Bool `json:"@parking_flag,string"`
-				BikeFlag        Bool `json:"@bike_flag,string"`
-				BikeStation     Bool `json:"@bike_station_flag,string"`
-				LockerFlag      Bool `json:"@locker_flag,string"`
-			} `json:"Station"`
+			StationAccess StationAccess `json:"Station"`
 		} `json:"Stations"`
 	}
 }
 
+// StationAccess is how to get to and around one station.
+type StationAccess struct {
+	Name            string
+	Abbr            string
+	Entering        CDATASection
+	Exiting         CDATASection
+	FillTime        CDATASection
+	CarShare        CDATASection
+	Lockers         CDATASection
+	BikeStationText CDATASection
+	Destinations    CDATASection
+	Link            string
+	ParkingFlag     Bool `json:"@parking_flag,string"`
+	BikeFlag        Bool `json:"@bike_flag,string"`
+	BikeStation     Bool `json:"@bike_station_flag,string"`
+	LockerFlag      Bool `json:"@locker_flag,string"`
+}
+
 // RequestStationInfo provides a detailed information about the specified
 // station. Pass in a 4-letter abbreviation for a station as the orig param. See
 // official docs at https://api.bart.gov/docs/stn/stninfo.aspx.
 func (a *StationsAPI) RequestStationInfo(orig string) (res StationInfoResponse, err error) {
+	return a.RequestStationInfoContext(context.Background(), orig)
+}
+
+// RequestStationInfoContext is like RequestStationInfo, but uses ctx for the
+// request.
+func (a *StationsAPI) RequestStationInfoContext(ctx context.Context, orig string) (res StationInfoResponse, err error) {
+	if orig, err = normalizeStation("orig", orig); err != nil {
+		return
+	}
 	params := initStationsRequest("stninfo", orig)
-	err = params.requestAPI(a, &res)
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -72,37 +95,46 @@ type StationInfoResponse struct {
 	Root struct {
 		ResponseMetaData
 		Data struct {
-			StationInfo struct {
-				Name           string
-				Abbr           string
-				Latitude       float32 `json:"gtfs_latitude,string"`
-				Longitude      float32 `json:"gtfs_longitude,string"`
-				Address        string
-				City           string
-				County         string
-				State          string
-				ZipCode        string
-				NorthRoutes    struct{ Route []string }    `json:"north_routes"`
-				SouthRoutes    struct{ Route []string }    `json:"south_routes"`
-				NorthPlatforms struct{ Platform []string } `json:"north_platforms"`
-				SouthPlatforms struct{ Platform []string } `json:"south_platforms"`
-				PlatformInfo   string                      `json:"platform_info"`
-				Intro          CDATASection
-				CrossStreet    CDATASection `json:"cross_street"`
-				Food           CDATASection
-				Shopping       CDATASection
-				Attraction     CDATASection
-				Link           CDATASection
-			} `json:"Station"`
+			StationInfo StationInfo `json:"Station"`
 		} `json:"Stations"`
 	}
 }
 
+// StationInfo is the details of one station, including the routes and
+// platforms serving it.
+type StationInfo struct {
+	Name           string
+	Abbr           string
+	Latitude       float32 `json:"gtfs_latitude,string"`
+	Longitude      float32 `json:"gtfs_longitude,string"`
+	Address        string
+	City           string
+	County         string
+	State          string
+	ZipCode        string
+	NorthRoutes    struct{ Route []string }    `json:"north_routes"`
+	SouthRoutes    struct{ Route []string }    `json:"south_routes"`
+	NorthPlatforms struct{ Platform []string } `json:"north_platforms"`
+	SouthPlatforms struct{ Platform []string } `json:"south_platforms"`
+	PlatformInfo   string                      `json:"platform_info"`
+	Intro          CDATASection
+	CrossStreet    CDATASection `json:"cross_street"`
+	Food           CDATASection
+	Shopping       CDATASection
+	Attraction     CDATASection
+	Link           CDATASection
+}
+
 // RequestStations provides a list of all available stations. See official docs
 // at https://api.bart.gov/docs/stn/stns.aspx.
 func (a *StationsAPI) RequestStations() (res StationsResponse, err error) {
+	return a.RequestStationsContext(context.Background())
+}
+
+// RequestStationsContext is like RequestStations, but uses ctx for the request.
+func (a *StationsAPI) RequestStationsContext(ctx context.Context) (res StationsResponse, err error) {
 	params := initStationsRequest("stns", "")
-	err = params.requestAPI(a, &res)
+	err = params.requestAPI(ctx, a, &res)
 	return
 }
 
@@ -111,17 +143,20 @@ type StationsResponse struct {
 	Root struct {
 		ResponseMetaData
 		Data struct {
-			List []struct {
-				Name      string
-				Abbr      string
-				Latitude  float32 `json:"gtfs_latitude,string"`
-				Longitude float32 `json:"gtfs_longitude,string"`
-				Address   string
-				City      string
-				County    string
-				State     string
-				ZipCode   string
-			} `json:"station"`
+			List []StationSummary `json:"station"`
 		} `json:"stations"`
 	}
 }
+
+// StationSummary is the name and location of one station.
+type StationSummary struct {
+	Name      string
+	Abbr      string
+	Latitude  float32 `json:"gtfs_latitude,string"`
+	Longitude float32 `json:"gtfs_longitude,string"`
+	Address   string
+	City      string
+	County    string
+	State     string
+	ZipCode   string
+}
@@ -0,0 +1,113 @@
+package bart
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// Embed the time zone database, so Location is available on systems
+	// without one.
+	_ "time/tzdata"
+)
+
+// Location is the time zone of the BART system. Times in API responses are in
+// this time zone.
+var Location = mustLoadLocation("America/Los_Angeles")
+
+const (
+	// DateLayout is the format of dates in API requests and responses, such as
+	// "10/17/2026".
+	DateLayout = "01/02/2006"
+	// ClockLayout is the format of times of day in API responses, such as
+	// "10:15 AM".
+	ClockLayout = "3:04 PM"
+)
+
+// serviceDayRollover is when one service day ends and the next one begins.
+// Trains running after midnight belong to the previous day's service, so BART
+// reports them with the previous day's date.
+const serviceDayRollover = 3 * time.Hour
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// ParseServiceTime combines a date and a time of day from an API response into
+// a time.Time in Location. The date is the service date, which BART keeps using
+// for trains running after midnight. So, a time of day before 3:00 AM is placed
+// on the following calendar day. For example, "12:11 AM" on "10/17/2026" is
+// parsed as 12:11 AM on October 18.
+func ParseServiceTime(date, clock string) (out time.Time, err error) {
+	day, err := time.ParseInLocation(DateLayout, strings.TrimSpace(date), Location)
+	if err != nil {
+		return out, fmt.Errorf("bart: invalid date %q: %w", date, err)
+	}
+	tod, err := time.Parse(ClockLayout, strings.TrimSpace(clock))
+	if err != nil {
+		return out, fmt.Errorf("bart: invalid time %q: %w", clock, err)
+	}
+
+	dayOffset := 0
+	if time.Duration(tod.Hour())*time.Hour+time.Duration(tod.Minute())*time.Minute < serviceDayRollover {
+		dayOffset = 1
+	}
+	// Build the time from its parts, rather than adding durations, so days
+	// where daylight saving time starts or ends come out right.
+	out = time.Date(day.Year(), day.Month(), day.Day()+dayOffset, tod.Hour(), tod.Minute(), 0, 0, Location)
+	return
+}
+
+// CivilDate is midnight in Location on the calendar date of t, as it is in the
+// location of t. Unlike a service date, a time before 3:00 AM stays on its own
+// calendar day. Use it to compare dates, or to step through them with AddDate.
+func CivilDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, Location)
+}
+
+// OrigTime is the departure time from the Origin station.
+func (d OrigDestTimeData) OrigTime() (time.Time, error) {
+	return ParseServiceTime(d.OrigTimeDate, d.OrigTimeMin)
+}
+
+// DestTime is the arrival time at the Destination station.
+func (d OrigDestTimeData) DestTime() (time.Time, error) {
+	return ParseServiceTime(d.DestTimeDate, d.DestTimeMin)
+}
+
+// Duration is the time between departing the Origin station and arriving at
+// the Destination station.
+func (d OrigDestTimeData) Duration() (out time.Duration, err error) {
+	orig, err := d.OrigTime()
+	if err != nil {
+		return
+	}
+	dest, err := d.DestTime()
+	if err != nil {
+		return
+	}
+	out = dest.Sub(orig)
+	return
+}
+
+// OrigTimeOn is the departure time from the station on the service date, which
+// is at StationSchedulesResponse.Root.Date.
+func (i StationScheduleItem) OrigTimeOn(date string) (time.Time, error) {
+	return ParseServiceTime(date, i.OrigTime)
+}
+
+// DestTimeOn is the arrival time at the train's final station on the service
+// date, which is at StationSchedulesResponse.Root.Date.
+func (i StationScheduleItem) DestTimeOn(date string) (time.Time, error) {
+	return ParseServiceTime(date, i.DestTime)
+}
+
+// OrigTimeOn is the departure time from the station on the service date, which
+// is at RouteSchedulesResponse.Root.Date.
+func (s ScheduledStop) OrigTimeOn(date string) (time.Time, error) {
+	return ParseServiceTime(date, s.OrigTime)
+}
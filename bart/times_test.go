@@ -0,0 +1,215 @@
+package bart
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseServiceTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		date     string
+		clock    string
+		expected time.Time
+	}{
+		{
+			name:     "morning",
+			date:     "10/17/2026",
+			clock:    "10:15 AM",
+			expected: time.Date(2026, time.October, 17, 10, 15, 0, 0, Location),
+		},
+		{
+			name:     "before midnight",
+			date:     "10/17/2026",
+			clock:    "11:48 PM",
+			expected: time.Date(2026, time.October, 17, 23, 48, 0, 0, Location),
+		},
+		{
+			name:     "after midnight",
+			date:     "10/17/2026",
+			clock:    "12:11 AM",
+			expected: time.Date(2026, time.October, 18, 0, 11, 0, 0, Location),
+		},
+		{
+			name:     "end of month",
+			date:     "10/31/2026",
+			clock:    "1:05 AM",
+			expected: time.Date(2026, time.November, 1, 1, 5, 0, 0, Location),
+		},
+		{
+			name:     "extra whitespace",
+			date:     "10/17/2026 ",
+			clock:    " 5:13 AM",
+			expected: time.Date(2026, time.October, 17, 5, 13, 0, 0, Location),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseServiceTime(test.date, test.clock)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(test.expected) {
+				t.Errorf("wrong time; got %v, expected %v", got, test.expected)
+			}
+			if got.Location() != Location {
+				t.Errorf("wrong location; got %v, expected %v", got.Location(), Location)
+			}
+		})
+	}
+
+	t.Run("daylight saving time", func(t *testing.T) {
+		summer, err := ParseServiceTime("10/17/2026", "8:00 AM")
+		if err != nil {
+			t.Fatal(err)
+		}
+		winter, err := ParseServiceTime("11/17/2026", "8:00 AM")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, offset := summer.Zone(); offset != -7*60*60 {
+			t.Errorf("wrong offset in summer; got %d, expected %d", offset, -7*60*60)
+		}
+		if _, offset := winter.Zone(); offset != -8*60*60 {
+			t.Errorf("wrong offset in winter; got %d, expected %d", offset, -8*60*60)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := ParseServiceTime("2026-10-17", "10:15 AM"); err == nil {
+			t.Error("expected error for invalid date")
+		}
+		if _, err := ParseServiceTime("10/17/2026", "10:15"); err == nil {
+			t.Error("expected error for invalid time")
+		}
+	})
+}
+
+func TestCivilDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       time.Time
+		expected time.Time
+	}{
+		{
+			name:     "after midnight",
+			in:       time.Date(2026, time.October, 18, 0, 11, 0, 0, Location),
+			expected: time.Date(2026, time.October, 18, 0, 0, 0, 0, Location),
+		},
+		{
+			name:     "end of daylight saving time",
+			in:       time.Date(2026, time.November, 1, 23, 30, 0, 0, Location),
+			expected: time.Date(2026, time.November, 1, 0, 0, 0, 0, Location),
+		},
+		{
+			name:     "other location",
+			in:       time.Date(2026, time.October, 18, 3, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, time.October, 18, 0, 0, 0, 0, Location),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := CivilDate(test.in)
+			if !got.Equal(test.expected) || got.Location() != Location {
+				t.Errorf("wrong date; got %v, expected %v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestOrigDestTimeData(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         OrigDestTimeData
+		expectedOrig time.Time
+		expectedDest time.Time
+		expectedDur  time.Duration
+	}{
+		{
+			name: "same day",
+			data: OrigDestTimeData{
+				OrigTimeMin: "8:08 AM", OrigTimeDate: "10/17/2026",
+				DestTimeMin: "9:09 AM", DestTimeDate: "10/17/2026",
+			},
+			expectedOrig: time.Date(2026, time.October, 17, 8, 8, 0, 0, Location),
+			expectedDest: time.Date(2026, time.October, 17, 9, 9, 0, 0, Location),
+			expectedDur:  61 * time.Minute,
+		},
+		{
+			name: "crosses midnight",
+			data: OrigDestTimeData{
+				OrigTimeMin: "11:48 PM", OrigTimeDate: "10/17/2026",
+				DestTimeMin: "12:49 AM", DestTimeDate: "10/17/2026",
+			},
+			expectedOrig: time.Date(2026, time.October, 17, 23, 48, 0, 0, Location),
+			expectedDest: time.Date(2026, time.October, 18, 0, 49, 0, 0, Location),
+			expectedDur:  61 * time.Minute,
+		},
+		{
+			// Daylight saving time ends at 2:00 AM on November 1, so the
+			// clock goes from 1:59 AM PDT back to 1:00 AM PST.
+			name: "daylight saving time ends",
+			data: OrigDestTimeData{
+				OrigTimeMin: "11:30 PM", OrigTimeDate: "10/31/2026",
+				DestTimeMin: "12:30 AM", DestTimeDate: "10/31/2026",
+			},
+			expectedOrig: time.Date(2026, time.October, 31, 23, 30, 0, 0, Location),
+			expectedDest: time.Date(2026, time.November, 1, 0, 30, 0, 0, Location),
+			expectedDur:  time.Hour,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			orig, err := test.data.OrigTime()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !orig.Equal(test.expectedOrig) {
+				t.Errorf("wrong OrigTime; got %v, expected %v", orig, test.expectedOrig)
+			}
+			dest, err := test.data.DestTime()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !dest.Equal(test.expectedDest) {
+				t.Errorf("wrong DestTime; got %v, expected %v", dest, test.expectedDest)
+			}
+			dur, err := test.data.Duration()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dur != test.expectedDur {
+				t.Errorf("wrong Duration; got %v, expected %v", dur, test.expectedDur)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		data := OrigDestTimeData{OrigTimeMin: "8:08 AM", OrigTimeDate: "10/17/2026"}
+		if _, err := data.Duration(); err == nil {
+			t.Error("expected error for missing DestTime")
+		}
+	})
+}
+
+func TestStationScheduleItem(t *testing.T) {
+	item := StationScheduleItem{OrigTime: "11:52 PM", DestTime: "12:42 AM"}
+
+	orig, err := item.OrigTimeOn("10/17/2026")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Date(2026, time.October, 17, 23, 52, 0, 0, Location); !orig.Equal(expected) {
+		t.Errorf("wrong OrigTimeOn; got %v, expected %v", orig, expected)
+	}
+	dest, err := item.DestTimeOn("10/17/2026")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Date(2026, time.October, 18, 0, 42, 0, 0, Location); !dest.Equal(expected) {
+		t.Errorf("wrong DestTimeOn; got %v, expected %v", dest, expected)
+	}
+}
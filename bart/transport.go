@@ -0,0 +1,72 @@
+package bart
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Middleware wraps an http.RoundTripper with another one. Use it to add auth
+// headers, tracing or logging to requests without wrapping the http.Client
+// yourself. The Middleware in a Config are applied in order, so the first one
+// sees each request first.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to use an ordinary function as an
+// http.RoundTripper. It's handy for writing Middleware.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// client is the HTTP client for making requests. It's resolved on every
+// request, so replacing the HTTP field of the Config takes effect on the next
+// one.
+func (c *Config) client() *http.Client {
+	if c.httpClient == nil {
+		return c.buildHTTPClient()
+	}
+	return c.httpClient.get(c)
+}
+
+// builtHTTPClient remembers the HTTP client with the Middleware applied, so the
+// Middleware is applied once, rather than on every request. It's built again
+// when the HTTP field of the Config is replaced.
+type builtHTTPClient struct {
+	mu    sync.Mutex
+	base  *http.Client
+	built *http.Client
+}
+
+func (b *builtHTTPClient) get(c *Config) *http.Client {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.built == nil || b.base != c.HTTP {
+		b.base = c.HTTP
+		b.built = c.buildHTTPClient()
+	}
+	return b.built
+}
+
+// buildHTTPClient applies the Middleware to a shallow copy of the HTTP client,
+// so the original is left alone.
+func (c *Config) buildHTTPClient() *http.Client {
+	base := c.HTTP
+	if base == nil {
+		base = &http.Client{}
+	}
+	if len(c.Middleware) < 1 {
+		return base
+	}
+
+	out := *base
+	transport := out.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		transport = c.Middleware[i](transport)
+	}
+	out.Transport = transport
+	return &out
+}
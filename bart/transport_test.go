@@ -0,0 +1,104 @@
+package bart
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfigEndpoint(t *testing.T) {
+	tests := []struct {
+		baseURL     string
+		expectedURL string
+		expectedErr bool
+	}{
+		{baseURL: "", expectedURL: "https://api.bart.gov/api/etd.aspx"},
+		{baseURL: "http://localhost:8080", expectedURL: "http://localhost:8080/etd.aspx"},
+		{baseURL: "http://localhost:8080/", expectedURL: "http://localhost:8080/etd.aspx"},
+		{baseURL: "https://proxy.example.com/bart/api/", expectedURL: "https://proxy.example.com/bart/api/etd.aspx"},
+		{baseURL: "api.bart.gov/api", expectedErr: true},
+		{baseURL: "ftp://api.bart.gov/api", expectedErr: true},
+		{baseURL: "https://api.bart.gov/api?key=foo", expectedErr: true},
+		{baseURL: "https://api.bart.gov/%zz", expectedErr: true},
+	}
+
+	for _, test := range tests {
+		conf := Config{BaseURL: test.baseURL}
+		got, err := conf.endpoint("/etd.aspx")
+		if test.expectedErr {
+			if err == nil {
+				t.Errorf("BaseURL %q: expected error, got %q", test.baseURL, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("BaseURL %q: unexpected error, %v", test.baseURL, err)
+			continue
+		}
+		if got.String() != test.expectedURL {
+			t.Errorf("BaseURL %q: got %q, expected %q", test.baseURL, got, test.expectedURL)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/mirror/stn.aspx" {
+			t.Errorf("wrong path; got %q, expected %q", r.URL.Path, "/mirror/stn.aspx")
+		}
+		if got := r.Header.Get("X-Trace"); got != "first,second" {
+			t.Errorf("wrong X-Trace header; got %q", got)
+		}
+		fmt.Fprint(w, `{"root":{"message":""}}`)
+	}))
+	defer server.Close()
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				req = req.Clone(req.Context())
+				vals := req.Header.Values("X-Trace")
+				req.Header.Set("X-Trace", strings.Join(append(vals, name), ","))
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	httpClient := &http.Client{}
+	client := NewClient(&Config{
+		HTTP:       httpClient,
+		BaseURL:    server.URL + "/mirror/",
+		Middleware: []Middleware{trace("first"), trace("second")},
+	})
+	if _, err := client.RequestStations(); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0] != "first" || calls[1] != "second" {
+		t.Errorf("wrong order of middleware calls, %q", calls)
+	}
+	if httpClient.Transport != nil {
+		t.Error("expected original HTTP client to be left alone")
+	}
+
+	// Replacing the HTTP client takes effect on the next request, with the
+	// same middleware.
+	var replaced int
+	client.conf.HTTP = &http.Client{Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		replaced++
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	calls = nil
+	if _, err := client.RequestStations(); err != nil {
+		t.Fatal(err)
+	}
+	if replaced != 1 {
+		t.Errorf("wrong number of calls to the new HTTP client; got %d, expected %d", replaced, 1)
+	}
+	if len(calls) != 2 {
+		t.Errorf("expected middleware to wrap the new HTTP client, %q", calls)
+	}
+}
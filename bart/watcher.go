@@ -0,0 +1,311 @@
+package bart
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WatchEventType is the kind of change in a WatchEvent.
+type WatchEventType int
+
+const (
+	// DepartureNew is for a train which wasn't in the previous estimates.
+	DepartureNew WatchEventType = iota + 1
+	// DepartureMinutesChanged is for a train with a new number of minutes
+	// until departure.
+	DepartureMinutesChanged
+	// DepartureDelayChanged is for a train with a new delay.
+	DepartureDelayChanged
+	// DepartureDeparted is for a train which was about to leave, and is no
+	// longer in the estimates.
+	DepartureDeparted
+	// DepartureCancelled is for a train which is flagged as cancelled, or
+	// which dropped out of the estimates well before it was due to leave.
+	DepartureCancelled
+	// WatchError is for a failed request. The Watcher keeps going, and waits
+	// longer before the next request.
+	WatchError
+)
+
+func (t WatchEventType) String() string {
+	switch t {
+	case DepartureNew:
+		return "new"
+	case DepartureMinutesChanged:
+		return "minutes changed"
+	case DepartureDelayChanged:
+		return "delay changed"
+	case DepartureDeparted:
+		return "departed"
+	case DepartureCancelled:
+		return "cancelled"
+	case WatchError:
+		return "error"
+	default:
+		return fmt.Sprintf("WatchEventType(%d)", int(t))
+	}
+}
+
+// WatchEvent is a change in the estimated departures, found by a Watcher.
+type WatchEvent struct {
+	Type WatchEventType
+	// Departure is the latest estimate for the train. For DepartureDeparted
+	// and DepartureCancelled, it's the last estimate before the train was gone.
+	Departure Departure
+	// Previous is the estimate for the train from the previous request. It's
+	// the zero-value for DepartureNew.
+	Previous Departure
+	// Err is the error for WatchError.
+	Err error
+	// Time is when the estimates were received.
+	Time time.Time
+}
+
+// A Watcher polls for estimated departures, and tracks each train from one
+// request to the next, so it can report what changed. Make one with
+// NewWatcher, set any optional fields, then call Watch.
+//
+// The BART API doesn't identify trains in its estimates, so trains are matched
+// up by station, destination, platform and line color, then by the minutes
+// until departure. This is usually right, but it's a guess.
+type Watcher struct {
+	// Interval is the time between requests. The default is 30 seconds.
+	Interval time.Duration
+	// Jitter is the fraction, from 0 to 1, of each interval that is
+	// randomized, so many Watchers don't make requests in lockstep.
+	Jitter float64
+	// MaxBackoff caps the time between requests while they are failing. Each
+	// failure doubles the interval, until a request succeeds. The default is
+	// 5 minutes.
+	MaxBackoff time.Duration
+
+	api    *EstimatesAPI
+	params EstimateParams
+	now    func() time.Time
+}
+
+// NewWatcher makes a Watcher which requests estimates from api. The params are
+// the same as for RequestEstimate, including "ALL" for every station.
+func NewWatcher(api *EstimatesAPI, params EstimateParams) *Watcher {
+	return &Watcher{
+		Interval:   30 * time.Second,
+		Jitter:     0.1,
+		MaxBackoff: 5 * time.Minute,
+		api:        api,
+		params:     params,
+		now:        time.Now,
+	}
+}
+
+// Watch starts polling in a goroutine and sends events to the output channel,
+// until ctx is done. Then the channel is closed. The first request reports
+// every train as a DepartureNew. Events are sent one at a time, so read them
+// promptly to keep the polling on schedule.
+func (w *Watcher) Watch(ctx context.Context) <-chan WatchEvent {
+	out := make(chan WatchEvent)
+	go w.run(ctx, out)
+	return out
+}
+
+func (w *Watcher) run(ctx context.Context, out chan<- WatchEvent) {
+	defer close(out)
+
+	var (
+		tracked  map[departureKey][]Departure
+		lastPoll time.Time
+		failures int
+	)
+	for {
+		res, err := w.api.RequestEstimateContext(ctx, w.params)
+		now := w.now()
+		if ctx.Err() != nil {
+			return
+		}
+
+		var events []WatchEvent
+		if err != nil {
+			failures++
+			events = []WatchEvent{{Type: WatchError, Err: err, Time: now}}
+		} else {
+			failures = 0
+			current := groupDepartures(res)
+			events = diffTracked(tracked, current, now.Sub(lastPoll))
+			for i := range events {
+				events[i].Time = now
+			}
+			tracked, lastPoll = current, now
+		}
+
+		for _, event := range events {
+			select {
+			case <-ctx.Done():
+				return
+			case out <- event:
+			}
+		}
+
+		if sleep(ctx, w.nextDelay(failures)) != nil {
+			return
+		}
+	}
+}
+
+func (w *Watcher) nextDelay(failures int) time.Duration {
+	return pollDelay(w.Interval, w.MaxBackoff, w.Jitter, failures)
+}
+
+// pollDelay is the time to wait before the next request, when polling. Each
+// consecutive failure doubles the interval, up to maxBackoff.
+func pollDelay(interval, maxBackoff time.Duration, jitter float64, failures int) time.Duration {
+	d := interval
+	if d <= 0 {
+		d = 30 * time.Second
+	}
+	if failures > 0 {
+		backoff := float64(d) * math.Pow(2, float64(failures))
+		if maxBackoff <= 0 {
+			maxBackoff = 5 * time.Minute
+		}
+		if backoff > float64(maxBackoff) {
+			backoff = float64(maxBackoff)
+		}
+		d = time.Duration(backoff)
+	}
+	return applyJitter(d, jitter)
+}
+
+// departureKey identifies a group of trains, which can only be told apart by
+// their minutes until departure.
+type departureKey struct {
+	station  string
+	dest     string
+	platform int
+	color    string
+}
+
+func keyOf(d Departure) departureKey {
+	return departureKey{
+		station:  strings.ToUpper(d.Station),
+		dest:     strings.ToUpper(d.Abbreviation),
+		platform: d.Platform,
+		color:    strings.ToUpper(d.Color),
+	}
+}
+
+func groupDepartures(res EstimatesResponse) map[departureKey][]Departure {
+	out := make(map[departureKey][]Departure)
+	for _, stn := range res.Root.Data {
+		for _, dep := range stn.Departures().SortByMinutes() {
+			key := keyOf(dep)
+			out[key] = append(out[key], dep)
+		}
+	}
+	return out
+}
+
+// diffTracked compares every group of trains in prev and cur. Events are
+// ordered by group, then by the order of trains in the group.
+func diffTracked(prev, cur map[departureKey][]Departure, elapsed time.Duration) []WatchEvent {
+	keys := make([]departureKey, 0, len(prev)+len(cur))
+	for key := range cur {
+		keys = append(keys, key)
+	}
+	for key := range prev {
+		if _, ok := cur[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.station != b.station {
+			return a.station < b.station
+		}
+		if a.dest != b.dest {
+			return a.dest < b.dest
+		}
+		if a.platform != b.platform {
+			return a.platform < b.platform
+		}
+		return a.color < b.color
+	})
+
+	var out []WatchEvent
+	for _, key := range keys {
+		out = append(out, diffDepartures(prev[key], cur[key], elapsed)...)
+	}
+	return out
+}
+
+// diffDepartures matches up the trains in one group, from the previous request
+// to the current one. Both lists are sorted by minutes. Trains leave from the
+// front of the list, and new trains are added to the back, so the match is an
+// offset: the number of trains that dropped off the front. Any previous trains
+// left over after matching have dropped off too. The chosen offset is the one
+// where the matched trains' minutes best agree with the time elapsed between
+// the requests.
+func diffDepartures(prev, cur []Departure, elapsed time.Duration) []WatchEvent {
+	elapsedMin := elapsed.Minutes()
+	expected := func(d Departure) float64 {
+		return float64(d.Minutes) - elapsedMin
+	}
+	matches := func(offset int) int {
+		if n := len(prev) - offset; n < len(cur) {
+			return n
+		}
+		return len(cur)
+	}
+
+	best, bestCost := 0, math.Inf(1)
+	for offset := 0; offset <= len(prev); offset++ {
+		n := matches(offset)
+		var cost float64
+		for i, before := range prev {
+			if i >= offset && i < offset+n {
+				cost += math.Pow(expected(before)-float64(cur[i-offset].Minutes), 2)
+			} else {
+				// Dropping a train that's still a ways off is unlikely.
+				cost += math.Max(expected(before)-1, 0) * 2
+			}
+		}
+		if cost < bestCost {
+			best, bestCost = offset, cost
+		}
+	}
+
+	var out []WatchEvent
+	n := matches(best)
+	for i, before := range prev {
+		if (i >= best && i < best+n) || before.CancelFlag {
+			// Either it's still there, or it's already been reported as
+			// cancelled.
+			continue
+		}
+		typ := DepartureDeparted
+		if expected(before) > 1 {
+			typ = DepartureCancelled
+		}
+		out = append(out, WatchEvent{Type: typ, Departure: before, Previous: before})
+	}
+	for i, dep := range cur {
+		if i >= n {
+			out = append(out, WatchEvent{Type: DepartureNew, Departure: dep})
+			continue
+		}
+		before := prev[best+i]
+		if dep.CancelFlag && !before.CancelFlag {
+			out = append(out, WatchEvent{Type: DepartureCancelled, Departure: dep, Previous: before})
+			continue
+		}
+		if dep.Minutes != before.Minutes {
+			out = append(out, WatchEvent{Type: DepartureMinutesChanged, Departure: dep, Previous: before})
+		}
+		if dep.Delay != before.Delay {
+			out = append(out, WatchEvent{Type: DepartureDelayChanged, Departure: dep, Previous: before})
+		}
+	}
+	return out
+}
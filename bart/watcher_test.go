@@ -0,0 +1,178 @@
+package bart
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDiffDepartures(t *testing.T) {
+	dep := func(minutes, delay int) Departure {
+		out := Departure{Station: "MCAR", Abbreviation: "ANTC"}
+		out.Minutes, out.Delay = Minute(minutes), delay
+		return out
+	}
+	cancelled := func(d Departure) Departure {
+		d.CancelFlag = true
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		prev     []Departure
+		cur      []Departure
+		expected []WatchEventType
+	}{
+		{
+			name:     "first request",
+			cur:      []Departure{dep(5, 0), dep(15, 0)},
+			expected: []WatchEventType{DepartureNew, DepartureNew},
+		},
+		{
+			name:     "counting down",
+			prev:     []Departure{dep(5, 0), dep(15, 0)},
+			cur:      []Departure{dep(4, 0), dep(14, 0)},
+			expected: []WatchEventType{DepartureMinutesChanged, DepartureMinutesChanged},
+		},
+		{
+			name:     "no change",
+			prev:     []Departure{dep(5, 0)},
+			cur:      []Departure{dep(5, 0)},
+			expected: nil,
+		},
+		{
+			name:     "departed",
+			prev:     []Departure{dep(0, 0), dep(12, 0)},
+			cur:      []Departure{dep(11, 0)},
+			expected: []WatchEventType{DepartureDeparted, DepartureMinutesChanged},
+		},
+		{
+			name:     "dropped before it was due",
+			prev:     []Departure{dep(5, 0), dep(15, 0)},
+			cur:      []Departure{dep(4, 0)},
+			expected: []WatchEventType{DepartureCancelled, DepartureMinutesChanged},
+		},
+		{
+			name:     "delayed",
+			prev:     []Departure{dep(5, 0)},
+			cur:      []Departure{dep(6, 120)},
+			expected: []WatchEventType{DepartureMinutesChanged, DepartureDelayChanged},
+		},
+		{
+			name:     "flagged as cancelled",
+			prev:     []Departure{dep(5, 0)},
+			cur:      []Departure{cancelled(dep(4, 0))},
+			expected: []WatchEventType{DepartureCancelled},
+		},
+		{
+			name:     "cancelled train is gone",
+			prev:     []Departure{cancelled(dep(4, 0))},
+			cur:      nil,
+			expected: nil,
+		},
+		{
+			name:     "new train at the end",
+			prev:     []Departure{dep(5, 0)},
+			cur:      []Departure{dep(4, 0), dep(20, 0)},
+			expected: []WatchEventType{DepartureMinutesChanged, DepartureNew},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := diffDepartures(test.prev, test.cur, time.Minute)
+			if len(got) != len(test.expected) {
+				t.Fatalf("wrong number of events; got %v, expected %v", got, test.expected)
+			}
+			for i, event := range got {
+				if event.Type != test.expected[i] {
+					t.Errorf("event %d; wrong Type; got %v, expected %v", i, event.Type, test.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	responses := []string{
+		`{"root":{"station":[{"name":"MacArthur","abbr":"MCAR","etd":[{"destination":"Antioch","abbreviation":"ANTC","estimate":[{"minutes":"5","platform":"3","color":"YELLOW"},{"minutes":"15","platform":"3","color":"YELLOW"}]}]}],"message":""}}`,
+		`{"root":{"message":{"error":{"text":"Something went wrong"}}}}`,
+		`{"root":{"station":[{"name":"MacArthur","abbr":"MCAR","etd":[{"destination":"Antioch","abbreviation":"ANTC","estimate":[{"minutes":"4","platform":"3","color":"YELLOW"},{"minutes":"14","platform":"3","color":"YELLOW"}]}]}],"message":""}}`,
+	}
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Query().Get("orig") != "mcar" {
+			t.Errorf("wrong orig; got %q, expected %q", r.URL.Query().Get("orig"), "mcar")
+		}
+		fmt.Fprint(w, responses[calls%len(responses)])
+		calls++
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.conf.BaseURL = server.URL
+
+	watcher := NewWatcher(client.EstimatesAPI, EstimateParams{Orig: "mcar"})
+	watcher.Interval = time.Millisecond
+	watcher.Jitter = 0
+	clock := time.Date(2026, time.October, 17, 8, 0, 0, 0, Location)
+	watcher.now = func() time.Time {
+		clock = clock.Add(30 * time.Second)
+		return clock
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := watcher.Watch(ctx)
+
+	expected := []WatchEventType{
+		DepartureNew, DepartureNew,
+		WatchError,
+		DepartureMinutesChanged, DepartureMinutesChanged,
+	}
+	for i, typ := range expected {
+		event := <-events
+		if event.Type != typ {
+			t.Errorf("event %d; wrong Type; got %v, expected %v", i, event.Type, typ)
+		}
+		if typ == WatchError && event.Err == nil {
+			t.Errorf("event %d; expected Err", i)
+		}
+		if event.Time.IsZero() {
+			t.Errorf("event %d; expected Time", i)
+		}
+	}
+
+	cancel()
+	for range events {
+		// Drain the channel until it's closed.
+	}
+}
+
+func TestWatcherNextDelay(t *testing.T) {
+	watcher := &Watcher{Interval: time.Second, MaxBackoff: 5 * time.Second}
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 0, expected: time.Second},
+		{failures: 1, expected: 2 * time.Second},
+		{failures: 2, expected: 4 * time.Second},
+		{failures: 3, expected: 5 * time.Second},
+	}
+	for _, test := range tests {
+		if got := watcher.nextDelay(test.failures); got != test.expected {
+			t.Errorf("failures %d; wrong delay; got %v, expected %v", test.failures, got, test.expected)
+		}
+	}
+}
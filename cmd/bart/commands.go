@@ -0,0 +1,566 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+)
+
+// A command is a subcommand of bart. It parses its own flags from args, makes a
+// request and returns the response as an output.
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (output, error)
+}
+
+// commands is every subcommand, in the order they're listed in the usage.
+var commands = []command{
+	{name: "etd", summary: "estimated departures from a station, or ALL stations", run: runETD},
+	{name: "stations", summary: "list every station", run: runStations},
+	{name: "station-info", summary: "details of a station", run: runStationInfo},
+	{name: "access", summary: "access and neighborhood information for a station", run: runAccess},
+	{name: "routes", summary: "list every route", run: runRoutes},
+	{name: "route-info", summary: "details of a route, or of every route", run: runRouteInfo},
+	{name: "trip", summary: "plan a trip; trip depart, or trip arrive", run: runTrip},
+	{name: "fare", summary: "fares for a trip", run: runFare},
+	{name: "holidays", summary: "list holidays and the schedule running on them", run: runHolidays},
+	{name: "special", summary: "list special schedule notices", run: runSpecial},
+	{name: "station-schedule", summary: "the day's schedule at a station", run: runStationSchedule},
+	{name: "route-schedule", summary: "the day's schedule on a route", run: runRouteSchedule},
+	{name: "advisories", summary: "service advisories, for every station or one", run: runAdvisories},
+	{name: "elevators", summary: "elevator outages, for every station or one", run: runElevators},
+	{name: "train-count", summary: "the number of trains running now", run: runTrainCount},
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func newFlagSet(name, args string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("bart "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: bart %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses the flags in args, and allows up to maxArgs arguments among
+// them. Flags may come before or after the arguments, such as "etd mcar -plat
+// 2", and everything after "--" is an argument.
+func parse(fs *flag.FlagSet, args []string, maxArgs int) error {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			return errUsage
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+	// Put the arguments back, so they're available from fs.Args.
+	if err := fs.Parse(append([]string{"--"}, positional...)); err != nil {
+		return errUsage
+	}
+	if fs.NArg() > maxArgs {
+		return usageError(fs, "unexpected arguments: %s", strings.Join(fs.Args()[maxArgs:], " "))
+	}
+	return nil
+}
+
+// usageError prints a problem with the flags or arguments, and the usage.
+func usageError(fs *flag.FlagSet, format string, args ...interface{}) error {
+	fmt.Fprintf(fs.Output(), "%s: %s\n", fs.Name(), fmt.Sprintf(format, args...))
+	fs.Usage()
+	return errUsage
+}
+
+// station gets a station abbreviation from the flag value, or else from the
+// only argument. If required, then it's a usage error if there isn't one.
+func station(fs *flag.FlagSet, val string, required bool) (string, error) {
+	if val != "" && fs.NArg() > 0 {
+		return "", usageError(fs, "station given twice, %q and %q", val, fs.Arg(0))
+	}
+	if val == "" && fs.NArg() > 0 {
+		val = fs.Arg(0)
+	}
+	if val == "" && required {
+		return "", usageError(fs, "a station is required")
+	}
+	return val, nil
+}
+
+func runETD(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	var p bart.EstimateParams
+	fs := newFlagSet("etd", "[station]", stderr)
+	fs.StringVar(&p.Orig, "orig", "", "station `abbreviation`, or ALL")
+	fs.StringVar(&p.Plat, "plat", "", "platform number, 1-4")
+	fs.StringVar(&p.Dir, "dir", "", "direction, n or s")
+	if err = parse(fs, args, 1); err != nil {
+		return
+	}
+	if p.Orig, err = station(fs, p.Orig, true); err != nil {
+		return
+	}
+
+	res, err := client.RequestEstimateContext(ctx, p)
+	if err != nil {
+		return
+	}
+	out.response = res
+	out.header = []string{"STATION", "DESTINATION", "MINUTES", "PLATFORM", "DIRECTION", "CARS", "COLOR", "DELAY", "BIKES"}
+	for _, stn := range res.Root.Data {
+		for _, d := range stn.Departures().SortByMinutes() {
+			out.add(
+				d.Station,
+				d.Destination,
+				minutes(d.Minutes),
+				strconv.Itoa(d.Platform),
+				d.Direction,
+				strconv.Itoa(d.Length),
+				d.Color,
+				strconv.Itoa(d.Delay),
+				yesNo(bool(d.BikeFlag)),
+			)
+		}
+	}
+	return
+}
+
+func runStations(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	fs := newFlagSet("stations", "", stderr)
+	if err = parse(fs, args, 0); err != nil {
+		return
+	}
+
+	res, err := client.RequestStationsContext(ctx)
+	if err != nil {
+		return
+	}
+	out.response = res
+	out.header = []string{"ABBR", "NAME", "CITY", "LATITUDE", "LONGITUDE"}
+	for _, stn := range res.Root.Data.List {
+		out.add(stn.Abbr, stn.Name, stn.City, coordinate(stn.Latitude), coordinate(stn.Longitude))
+	}
+	return
+}
+
+func runStationInfo(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	fs := newFlagSet("station-info", "[station]", stderr)
+	orig := fs.String("orig", "", "station `abbreviation`")
+	if err = parse(fs, args, 1); err != nil {
+		return
+	}
+	if *orig, err = station(fs, *orig, true); err != nil {
+		return
+	}
+
+	res, err := client.RequestStationInfoContext(ctx, *orig)
+	if err != nil {
+		return
+	}
+	stn := res.Root.Data.StationInfo
+	out.response = res
+	out.header = []string{"FIELD", "VALUE"}
+	out.add("Name", stn.Name)
+	out.add("Abbr", stn.Abbr)
+	out.add("Address", fmt.Sprintf("%s, %s, %s %s", stn.Address, stn.City, stn.State, stn.ZipCode))
+	out.add("Location", coordinate(stn.Latitude)+","+coordinate(stn.Longitude))
+	out.add("North routes", strings.Join(stn.NorthRoutes.Route, " "))
+	out.add("South routes", strings.Join(stn.SouthRoutes.Route, " "))
+	out.add("North platforms", strings.Join(stn.NorthPlatforms.Platform, " "))
+	out.add("South platforms", strings.Join(stn.SouthPlatforms.Platform, " "))
+	out.add("Platform info", stn.PlatformInfo)
+	out.add("Cross street", stn.CrossStreet.Value)
+	out.add("Intro", stn.Intro.Value)
+	out.add("Link", stn.Link.Value)
+	return
+}
+
+func runAccess(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	fs := newFlagSet("access", "[station]", stderr)
+	orig := fs.String("orig", "", "station `abbreviation`")
+	if err = parse(fs, args, 1); err != nil {
+		return
+	}
+	if *orig, err = station(fs, *orig, true); err != nil {
+		return
+	}
+
+	res, err := client.RequestStationAccessContext(ctx, *orig)
+	if err != nil {
+		return
+	}
+	stn := res.Root.Data.StationAccess
+	out.response = res
+	out.header = []string{"FIELD", "VALUE"}
+	out.add("Name", stn.Name)
+	out.add("Abbr", stn.Abbr)
+	out.add("Parking", yesNo(bool(stn.ParkingFlag)))
+	out.add("Bikes", yesNo(bool(stn.BikeFlag)))
+	out.add("Bike station", yesNo(bool(stn.BikeStation)))
+	out.add("Lockers", yesNo(bool(stn.LockerFlag)))
+	out.add("Entering", stn.Entering.Value)
+	out.add("Exiting", stn.Exiting.Value)
+	out.add("Fill time", stn.FillTime.Value)
+	out.add("Car share", stn.CarShare.Value)
+	out.add("Link", stn.Link)
+	return
+}
+
+// routeFlags adds the flags of bart.RouteParams to fs.
+func routeFlags(fs *flag.FlagSet, p *bart.RouteParams, withRoute bool) {
+	if withRoute {
+		fs.IntVar(&p.Route, "route", 0, "route `number`, or 0 for every route")
+	}
+	fs.IntVar(&p.Sched, "sched", 0, "schedule `number`, or 0 for the current schedule")
+	fs.StringVar(&p.Date, "date", "", "`mm/dd/yyyy`, or empty for today")
+}
+
+func runRoutes(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	var p bart.RouteParams
+	fs := newFlagSet("routes", "", stderr)
+	routeFlags(fs, &p, false)
+	if err = parse(fs, args, 0); err != nil {
+		return
+	}
+
+	res, err := client.RequestRoutesWithParamsContext(ctx, p)
+	if err != nil {
+		return
+	}
+	out.response = res
+	out.header = []string{"NUMBER", "NAME", "ABBR", "COLOR"}
+	for _, route := range res.Root.Data.List {
+		out.add(strconv.Itoa(route.Number), route.Name, route.Abbr, route.Color)
+	}
+	return
+}
+
+func runRouteInfo(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	var p bart.RouteParams
+	fs := newFlagSet("route-info", "", stderr)
+	routeFlags(fs, &p, true)
+	if err = parse(fs, args, 0); err != nil {
+		return
+	}
+
+	res, err := client.RequestRouteInfoContext(ctx, p)
+	if err != nil {
+		return
+	}
+	out.response = res
+	out.header = []string{"NUMBER", "NAME", "COLOR", "DIRECTION", "STATIONS"}
+	for _, route := range res.Root.Data.List {
+		out.add(
+			strconv.Itoa(route.Number),
+			route.Name,
+			route.Color,
+			route.Direction,
+			strings.Join(route.Config.Stations, " "),
+		)
+	}
+	return
+}
+
+func runTrip(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	var request func(context.Context, bart.TripParams) (bart.TripsResponse, error)
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	}
+	switch name {
+	case "depart":
+		request = client.RequestDeparturesContext
+	case "arrive":
+		request = client.RequestArrivalsContext
+	default:
+		fs := newFlagSet("trip", "depart|arrive [flags]", stderr)
+		if name == "-h" || name == "-help" || name == "--help" {
+			fs.Usage()
+			return out, flag.ErrHelp
+		}
+		return out, usageError(fs, "expected depart or arrive, got %q", name)
+	}
+
+	var p bart.TripParams
+	fs := newFlagSet("trip "+name, "", stderr)
+	fs.StringVar(&p.Orig, "orig", "", "origin station `abbreviation`")
+	fs.StringVar(&p.Dest, "dest", "", "destination station `abbreviation`")
+	fs.StringVar(&p.Time, "time", "", "`h:mm+am` or h:mm+pm, or empty for now")
+	fs.StringVar(&p.Date, "date", "", "`mm/dd/yyyy`, or empty for today")
+	fs.IntVar(&p.Before, "before", 0, "number of trips before the time, 0-4")
+	fs.IntVar(&p.After, "after", 2, "number of trips after the time, 0-4")
+	fs.BoolVar(&p.Legend, "legend", false, "include the legend in the response")
+	if err = parse(fs, args[1:], 0); err != nil {
+		return
+	}
+	if p.Orig == "" || p.Dest == "" {
+		return out, usageError(fs, "-orig and -dest are required")
+	}
+
+	res, err := request(ctx, p)
+	if err != nil {
+		return
+	}
+	out.response = res
+	out.header = []string{"TRIP", "LEG", "FROM", "DEPART", "TO", "ARRIVE", "TOWARDS", "DURATION"}
+	for i, trip := range res.Root.Data.Request.List {
+		for _, leg := range trip.Legs {
+			out.add(
+				strconv.Itoa(i+1),
+				strconv.Itoa(leg.Order),
+				leg.Origin,
+				leg.OrigTimeMin,
+				leg.Destination,
+				leg.DestTimeMin,
+				leg.TrainHeadStation,
+				strconv.Itoa(trip.TripTime),
+			)
+		}
+	}
+	return
+}
+
+func runFare(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	var p bart.FareParams
+	fs := newFlagSet("fare", "", stderr)
+	fs.StringVar(&p.Orig, "orig", "", "origin station `abbreviation`")
+	fs.StringVar(&p.Dest, "dest", "", "destination station `abbreviation`")
+	fs.StringVar(&p.Date, "date", "", "`mm/dd/yyyy`, or empty for today")
+	fs.IntVar(&p.Sched, "sched", 0, "schedule `number`, or 0 for the current schedule")
+	if err = parse(fs, args, 0); err != nil {
+		return
+	}
+	if p.Orig == "" || p.Dest == "" {
+		return out, usageError(fs, "-orig and -dest are required")
+	}
+
+	res, err := client.RequestFareWithParamsContext(ctx, p)
+	if err != nil {
+		return
+	}
+	out.response = res
+	out.header = []string{"CLASS", "NAME", "AMOUNT"}
+	for _, fare := range res.Root.Data.List {
+		out.add(string(fare.Class), fare.Name, strconv.FormatFloat(fare.Amount, 'f', 2, 64))
+	}
+	return
+}
+
+func runHolidays(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	fs := newFlagSet("holidays", "", stderr)
+	if err = parse(fs, args, 0); err != nil {
+		return
+	}
+
+	res, err := client.RequestHolidaySchedulesContext(ctx)
+	if err != nil {
+		return
+	}
+	out.response = res
+	out.header = []string{"DATE", "NAME", "SCHEDULE"}
+	for _, data := range res.Root.Data {
+		for _, holiday := range data.List {
+			out.add(holiday.Date, holiday.Name, holiday.ScheduleType)
+		}
+	}
+	return
+}
+
+func runSpecial(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	fs := newFlagSet("special", "", stderr)
+	if err = parse(fs, args, 0); err != nil {
+		return
+	}
+
+	res, err := client.RequestSpecialSchedulesContext(ctx)
+	if err != nil {
+		return
+	}
+	out.response = res
+	out.header = []string{"START", "END", "ORIG", "DEST", "ROUTES", "TEXT"}
+	for _, s := range res.Root.Data.List {
+		out.add(
+			strings.TrimSpace(s.StartDate+" "+s.StartTime),
+			strings.TrimSpace(s.EndDate+" "+s.EndTime),
+			s.Orig,
+			s.Dest,
+			s.RoutesAffected,
+			s.Text.Value,
+		)
+	}
+	return
+}
+
+func runStationSchedule(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	var orig, date string
+	fs := newFlagSet("station-schedule", "[station]", stderr)
+	fs.StringVar(&orig, "orig", "", "station `abbreviation`")
+	fs.StringVar(&date, "date", "", "`mm/dd/yyyy`, or empty for today")
+	if err = parse(fs, args, 1); err != nil {
+		return
+	}
+	if orig, err = station(fs, orig, true); err != nil {
+		return
+	}
+
+	res, err := client.RequestStationSchedulesContext(ctx, orig, date)
+	if err != nil {
+		return
+	}
+	out.response = res
+	out.header = []string{"TIME", "LINE", "TOWARDS", "ARRIVE", "TRAIN"}
+	for _, item := range res.Root.Data.List {
+		out.add(item.OrigTime, item.Line, item.TrainHeadStation, item.DestTime, item.TrainID)
+	}
+	return
+}
+
+func runRouteSchedule(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	var p bart.RouteScheduleParams
+	fs := newFlagSet("route-schedule", "", stderr)
+	fs.IntVar(&p.Route, "route", 0, "route `number`")
+	fs.StringVar(&p.Date, "date", "", "`mm/dd/yyyy`, or empty for today")
+	fs.StringVar(&p.Time, "time", "", "`h:mm+am` or h:mm+pm, or empty for the whole day")
+	fs.BoolVar(&p.Legend, "legend", false, "include the legend in the response")
+	fs.IntVar(&p.Sched, "sched", 0, "schedule `number`, or 0 for the current schedule")
+	if err = parse(fs, args, 0); err != nil {
+		return
+	}
+	if p.Route == 0 {
+		return out, usageError(fs, "-route is required")
+	}
+
+	res, err := client.RequestRouteSchedulesWithParamsContext(ctx, p)
+	if err != nil {
+		return
+	}
+	out.response = res
+	out.header = []string{"TRAIN", "STATION", "TIME"}
+	for _, train := range res.Root.Data.List {
+		for _, stop := range train.Stops {
+			if stop.OrigTime == "" {
+				continue // The train passes the station without stopping.
+			}
+			out.add(strconv.Itoa(train.Index), stop.Station, stop.OrigTime)
+		}
+	}
+	return
+}
+
+func runAdvisories(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	fs := newFlagSet("advisories", "[station]", stderr)
+	orig := fs.String("orig", "", "station `abbreviation`, or empty for every station")
+	if err = parse(fs, args, 1); err != nil {
+		return
+	}
+	if *orig, err = station(fs, *orig, false); err != nil {
+		return
+	}
+
+	var res bart.AdvisoriesBSAResponse
+	if *orig == "" {
+		res, err = client.RequestBSAContext(ctx)
+	} else {
+		res, err = client.RequestStationBSAContext(ctx, *orig)
+	}
+	if err != nil {
+		return
+	}
+	out.response = res
+	advisoryRows(&out, res.Root.Data)
+	return
+}
+
+func runElevators(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	fs := newFlagSet("elevators", "[station]", stderr)
+	orig := fs.String("orig", "", "station `abbreviation`, or empty for every station")
+	if err = parse(fs, args, 1); err != nil {
+		return
+	}
+	if *orig, err = station(fs, *orig, false); err != nil {
+		return
+	}
+
+	var res bart.AdvisoriesElevatorResponse
+	if *orig == "" {
+		res, err = client.RequestElevatorContext(ctx)
+	} else {
+		res, err = client.RequestStationElevatorContext(ctx, *orig)
+	}
+	if err != nil {
+		return
+	}
+	out.response = res
+	advisories := make([]bart.Advisory, len(res.Root.Data))
+	for i, elev := range res.Root.Data {
+		advisories[i] = elev.Advisory()
+	}
+	advisoryRows(&out, advisories)
+	return
+}
+
+func advisoryRows(out *output, advisories []bart.Advisory) {
+	out.header = []string{"STATION", "TYPE", "POSTED", "EXPIRES", "DESCRIPTION"}
+	for _, adv := range advisories {
+		out.add(adv.Station, string(adv.Type), adv.Posted, adv.Expires, adv.Description.Value)
+	}
+}
+
+func runTrainCount(ctx context.Context, client *bart.Client, args []string, stderr io.Writer) (out output, err error) {
+	fs := newFlagSet("train-count", "", stderr)
+	if err = parse(fs, args, 0); err != nil {
+		return
+	}
+
+	res, err := client.RequestTrainCountContext(ctx)
+	if err != nil {
+		return
+	}
+	out.response = res
+	out.header = []string{"TIME", "TRAINS"}
+	out.add(res.Root.Time, strconv.Itoa(res.Root.Data))
+	return
+}
+
+func minutes(m bart.Minute) string {
+	if m == 0 {
+		return "Leaving"
+	}
+	return strconv.Itoa(int(m))
+}
+
+func coordinate(val float32) string {
+	return strconv.FormatFloat(float64(val), 'f', -1, 32)
+}
+
+func yesNo(val bool) string {
+	if val {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,113 @@
+// Command bart looks up BART information from a terminal. It has a subcommand
+// for each endpoint of the BART API, which prints the response as a table, JSON
+// or CSV.
+//
+//	bart [flags] <command> [command flags]
+//
+// For example, to list the next trains leaving MacArthur station:
+//
+//	bart etd -orig mcar
+//
+// Run bart -h for the list of commands, and bart <command> -h for the flags of
+// each one. The flags of bart itself, such as -o, go before the command, and
+// command flags may go before or after its arguments. The API key is read from
+// the -key flag, or the BART_API_KEY environment variable, and the public key
+// is used if neither is set.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+)
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command line in args, and returns the exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bart", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		key     = fs.String("key", os.Getenv("BART_API_KEY"), "BART API key")
+		baseURL = fs.String("base-url", "", "base URL of the BART API, for testing")
+		format  = fs.String("o", "table", "output format: table, json or csv")
+		timeout = fs.Duration("timeout", 10*time.Second, "time limit for the request")
+	)
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	w, err := newWriter(*format, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "bart: %v\n", err)
+		return 2
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+	cmd, ok := findCommand(fs.Arg(0))
+	if !ok {
+		fmt.Fprintf(stderr, "bart: unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return 2
+	}
+
+	client := bart.NewClient(&bart.Config{Key: *key, BaseURL: *baseURL})
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	out, err := cmd.run(ctx, client, fs.Args()[1:], stderr)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) && !errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "bart %s: %v\n", cmd.name, err)
+		}
+		return exitCode(err)
+	}
+	if err = w.write(out); err != nil {
+		fmt.Fprintf(stderr, "bart: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// errUsage means that a command was called with bad flags or arguments. The
+// problem and the command's usage have already been printed.
+var errUsage = errors.New("usage")
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage),
+		errors.Is(err, bart.ErrInvalidOrig),
+		errors.Is(err, bart.ErrInvalidDest),
+		errors.Is(err, bart.ErrInvalidRoute),
+		errors.Is(err, bart.ErrInvalidSched):
+		return 2
+	default:
+		return 1
+	}
+}
+
+func usage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintf(out, "Usage: bart [flags] <command> [command flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-17s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(out, "\nFlags:\n")
+	fs.PrintDefaults()
+}
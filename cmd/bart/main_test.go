@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rafaelespinoza/bart-go/bart/barttest"
+)
+
+func TestRun(t *testing.T) {
+	server := barttest.NewServer()
+	defer server.Close()
+
+	bart := func(args ...string) (code int, stdout, stderr string) {
+		var outBuf, errBuf bytes.Buffer
+		args = append([]string{"-base-url", server.URL}, args...)
+		code = run(context.Background(), args, &outBuf, &errBuf)
+		return code, outBuf.String(), errBuf.String()
+	}
+
+	t.Run("every command", func(t *testing.T) {
+		tests := [][]string{
+			{"etd", "mcar"},
+			{"etd", "-orig", "ALL"},
+			{"etd", "12th", "-plat", "2"},
+			{"etd", "-dir", "s", "--", "12th"},
+			{"stations"},
+			{"station-info", "-orig", "12th"},
+			{"access", "12th"},
+			{"routes"},
+			{"route-info", "-route", "1"},
+			{"trip", "depart", "-orig", "ashb", "-dest", "civc"},
+			{"trip", "arrive", "-orig", "ashb", "-dest", "civc", "-before", "1", "-after", "1"},
+			{"fare", "-orig", "12th", "-dest", "embr"},
+			{"holidays"},
+			{"special"},
+			{"station-schedule", "12th"},
+			{"route-schedule", "-route", "6"},
+			{"advisories"},
+			{"advisories", "12th"},
+			{"elevators"},
+			{"train-count"},
+		}
+		for _, args := range tests {
+			t.Run(strings.Join(args, " "), func(t *testing.T) {
+				code, stdout, stderr := bart(args...)
+				if code != 0 {
+					t.Fatalf("wrong exit code; got %d, expected %d; stderr: %s", code, 0, stderr)
+				}
+				lines := strings.Split(strings.TrimSpace(stdout), "\n")
+				if len(lines) < 2 {
+					t.Errorf("expected a header and at least one row, got %q", stdout)
+				}
+			})
+		}
+	})
+
+	t.Run("etd", func(t *testing.T) {
+		_, stdout, _ := bart("etd", "mcar")
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		if !strings.HasPrefix(lines[0], "STATION") {
+			t.Errorf("expected a header, got %q", lines[0])
+		}
+		for _, line := range lines[1:] {
+			if !strings.HasPrefix(line, "MCAR") {
+				t.Errorf("expected only MCAR departures, got %q", line)
+			}
+		}
+		server.AssertRequested(t, "etd", map[string]string{"orig": "mcar"})
+	})
+
+	t.Run("flags after arguments", func(t *testing.T) {
+		server.Reset()
+		code, _, stderr := bart("etd", "12th", "-plat", "2")
+		if code != 0 {
+			t.Fatalf("wrong exit code; got %d, expected %d; stderr: %s", code, 0, stderr)
+		}
+		server.AssertRequested(t, "etd", map[string]string{"orig": "12th", "plat": "2"})
+	})
+
+	t.Run("trip flags", func(t *testing.T) {
+		server.Reset()
+		code, _, stderr := bart("trip", "depart", "-orig", "ashb", "-dest", "civc", "-time", "9:00am", "-date", "10/17/2026", "-before", "1", "-after", "3", "-legend")
+		if code != 0 {
+			t.Fatalf("wrong exit code; got %d, expected %d; stderr: %s", code, 0, stderr)
+		}
+		server.AssertRequested(t, "depart", map[string]string{
+			"orig": "ashb",
+			"dest": "civc",
+			"time": "9:00am",
+			"date": "10/17/2026",
+			"b":    "1",
+			"a":    "3",
+			"l":    "1",
+		})
+	})
+
+	t.Run("json", func(t *testing.T) {
+		code, stdout, stderr := bart("-o", "json", "train-count")
+		if code != 0 {
+			t.Fatalf("wrong exit code; got %d, expected %d; stderr: %s", code, 0, stderr)
+		}
+		var res struct {
+			Root struct {
+				TrainCount int `json:",string"`
+			}
+		}
+		if err := json.Unmarshal([]byte(stdout), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Root.TrainCount < 1 {
+			t.Errorf("expected some trains, got %d", res.Root.TrainCount)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		code, stdout, stderr := bart("-o", "csv", "stations")
+		if code != 0 {
+			t.Fatalf("wrong exit code; got %d, expected %d; stderr: %s", code, 0, stderr)
+		}
+		records, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) < 2 || records[0][0] != "ABBR" {
+			t.Errorf("expected a header and rows, got %v", records)
+		}
+		for i, record := range records {
+			if len(record) != len(records[0]) {
+				t.Errorf("record %d; wrong number of fields; got %d, expected %d", i, len(record), len(records[0]))
+			}
+		}
+	})
+
+	t.Run("usage errors", func(t *testing.T) {
+		tests := []struct {
+			args     []string
+			expected string
+		}{
+			{args: nil, expected: "Usage: bart"},
+			{args: []string{"nope"}, expected: `unknown command "nope"`},
+			{args: []string{"-o", "xml", "stations"}, expected: "unknown output format"},
+			{args: []string{"etd"}, expected: "a station is required"},
+			{args: []string{"etd", "-nope"}, expected: "flag provided but not defined"},
+			{args: []string{"stations", "extra"}, expected: "unexpected arguments: extra"},
+			{args: []string{"etd", "mcar", "12th"}, expected: "unexpected arguments: 12th"},
+			{args: []string{"etd", "mcar", "-nope"}, expected: "flag provided but not defined"},
+			{args: []string{"etd", "-orig", "mcar", "12th"}, expected: "station given twice"},
+			{args: []string{"trip", "-orig", "ashb"}, expected: "expected depart or arrive"},
+			{args: []string{"trip", "depart", "-orig", "ashb"}, expected: "-orig and -dest are required"},
+			{args: []string{"route-schedule"}, expected: "-route is required"},
+			{args: []string{"etd", "nope"}, expected: `invalid station abbreviation "nope"`},
+		}
+		for _, test := range tests {
+			code, _, stderr := bart(test.args...)
+			if code != 2 {
+				t.Errorf("%v; wrong exit code; got %d, expected %d", test.args, code, 2)
+			}
+			if !strings.Contains(stderr, test.expected) {
+				t.Errorf("%v; expected stderr to contain %q, got %q", test.args, test.expected, stderr)
+			}
+		}
+	})
+
+	t.Run("help", func(t *testing.T) {
+		for _, args := range [][]string{{"-h"}, {"etd", "-h"}, {"trip", "-h"}} {
+			if code, _, _ := bart(args...); code != 0 {
+				t.Errorf("%v; wrong exit code; got %d, expected %d", args, code, 0)
+			}
+		}
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		server.FailNext("count", barttest.Failure{Shape: barttest.ErrorJSONObject, StatusCode: 500, Text: "Internal error"})
+		code, _, stderr := bart("train-count")
+		if code != 1 {
+			t.Errorf("wrong exit code; got %d, expected %d", code, 1)
+		}
+		if !strings.Contains(stderr, "bart train-count: ") {
+			t.Errorf("expected the error to be printed, got %q", stderr)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// output is the result of a command. The response is written as-is in the JSON
+// format, and the header and rows are written in the table and CSV formats.
+type output struct {
+	response interface{}
+	header   []string
+	rows     [][]string
+}
+
+func (o *output) add(row ...string) { o.rows = append(o.rows, row) }
+
+// writer writes an output in one format.
+type writer interface {
+	write(out output) error
+}
+
+func newWriter(format string, w io.Writer) (writer, error) {
+	switch strings.ToLower(format) {
+	case "table":
+		return tableWriter{w}, nil
+	case "json":
+		return jsonWriter{w}, nil
+	case "csv":
+		return csvWriter{w}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected table, json or csv", format)
+	}
+}
+
+// tableWriter lines up the rows in columns, for people to read.
+type tableWriter struct{ w io.Writer }
+
+func (t tableWriter) write(out output) error {
+	tw := tabwriter.NewWriter(t.w, 0, 0, 2, ' ', 0)
+	writeRow := func(row []string) {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			// Tabs and line breaks would throw the columns out of line.
+			cells[i] = strings.Join(strings.Fields(cell), " ")
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	writeRow(out.header)
+	for _, row := range out.rows {
+		writeRow(row)
+	}
+	return tw.Flush()
+}
+
+// jsonWriter writes the whole response, indented.
+type jsonWriter struct{ w io.Writer }
+
+func (j jsonWriter) write(out output) error {
+	enc := json.NewEncoder(j.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out.response)
+}
+
+// csvWriter writes a header line and then the rows, for other programs to read.
+type csvWriter struct{ w io.Writer }
+
+func (c csvWriter) write(out output) error {
+	cw := csv.NewWriter(c.w)
+	if err := cw.Write(out.header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(out.rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+)
+
+// cache is an in-memory LRU cache of response bodies, built on bart.LRUCache.
+// Unlike a bart.Cache, it keeps entries around for maxStale after they expire,
+// so they can be served when the BART API is down.
+type cache struct {
+	lru      *bart.LRUCache
+	maxStale time.Duration
+}
+
+type cacheEntry struct {
+	body    []byte
+	fetched time.Time
+	expires time.Time
+}
+
+// fresh reports whether the entry can be served without asking the BART API.
+func (e *cacheEntry) fresh(now time.Time) bool { return now.Before(e.expires) }
+
+// age is how long ago the entry was fetched, in whole seconds.
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	return now.Sub(e.fetched).Truncate(time.Second)
+}
+
+// cacheHeaderLen is the size of the times stored before the body of an entry.
+const cacheHeaderLen = 16
+
+// newCache makes a cache which holds up to size entries. A size less than 1
+// means there is no limit.
+func newCache(size int, maxStale time.Duration) *cache {
+	return &cache{lru: bart.NewLRUCache(size), maxStale: maxStale}
+}
+
+// get returns the entry for key, fresh or stale. Entries which have been
+// expired for longer than maxStale are not returned.
+func (c *cache) get(key string, now time.Time) (*cacheEntry, bool) {
+	val, ok := c.lru.Get(key)
+	if !ok || len(val) < cacheHeaderLen {
+		return nil, false
+	}
+	entry := &cacheEntry{
+		body:    val[cacheHeaderLen:],
+		fetched: time.Unix(0, int64(binary.BigEndian.Uint64(val[:8]))),
+		expires: time.Unix(0, int64(binary.BigEndian.Uint64(val[8:cacheHeaderLen]))),
+	}
+	if now.After(entry.expires.Add(c.maxStale)) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// set stores body for key. The bart.LRUCache holds onto it for the stale
+// window too, and get tells fresh entries from stale ones.
+func (c *cache) set(key string, body []byte, now time.Time, ttl time.Duration) {
+	expires := now.Add(ttl)
+	val := make([]byte, cacheHeaderLen, cacheHeaderLen+len(body))
+	binary.BigEndian.PutUint64(val[:8], uint64(now.UnixNano()))
+	binary.BigEndian.PutUint64(val[8:], uint64(expires.UnixNano()))
+	c.lru.Set(key, append(val, body...), ttl+c.maxStale)
+}
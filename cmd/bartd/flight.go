@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// flights collapses concurrent requests for the same key into one call, and
+// shares the result with every caller.
+type flights struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// do calls fn, unless there's already a call in flight for key, in which case
+// it waits for that one instead. The call isn't bound to ctx, since other
+// callers may be waiting on it, but a caller stops waiting when its ctx is
+// done.
+func (f *flights) do(ctx context.Context, key string, fn func() ([]byte, error)) ([]byte, error) {
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = make(map[string]*flight)
+	}
+	call, ok := f.calls[key]
+	if !ok {
+		call = &flight{done: make(chan struct{})}
+		f.calls[key] = call
+		go func() {
+			call.body, call.err = fn()
+			f.mu.Lock()
+			delete(f.calls, key)
+			f.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.body, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
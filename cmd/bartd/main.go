@@ -0,0 +1,68 @@
+// Command bartd is a caching proxy for the BART API. It serves the same paths
+// as api.bart.gov, so services can point their BaseURL at it instead:
+//
+//	bartd -addr :8080 -key $BART_API_KEY
+//
+// Every request to the BART API is made with bartd's own key, so callers don't
+// need one. Responses are cached for as long as bart.DefaultCacheTTL says, and
+// concurrent requests for the same response share one request to the BART API.
+// If the BART API fails, then a stale response is served for up to -max-stale
+// after it expires, with a Warning header. The X-Cache response header says
+// whether the response was a HIT, a MISS or STALE.
+//
+// Responses are always JSON, as if the json=y parameter were set.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+)
+
+func main() {
+	var (
+		addr      = flag.String("addr", ":8080", "address to listen on")
+		key       = flag.String("key", os.Getenv("BART_API_KEY"), "BART API key, or the public key if empty")
+		upstream  = flag.String("upstream", bart.DefaultBaseURL, "base URL of the BART API")
+		cacheSize = flag.Int("cache-size", 10000, "maximum number of cached responses, or 0 for no limit")
+		maxStale  = flag.Duration("max-stale", time.Hour, "how long to serve expired responses when the BART API fails")
+		timeout   = flag.Duration("timeout", 10*time.Second, "time limit for requests to the BART API, including retries")
+	)
+	flag.Parse()
+
+	client := bart.NewClient(&bart.Config{
+		Key:     *key,
+		BaseURL: *upstream,
+		Retry:   bart.DefaultRetryPolicy(),
+	})
+	handler := newProxy(client, newCache(*cacheSize, *maxStale), *timeout, log.Printf)
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("shutdown: %v", err)
+		}
+	}()
+
+	log.Printf("proxying %s on %s", *upstream, *addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+)
+
+// routes are the paths of the BART API which are proxied.
+var routes = map[string]bool{
+	"/bsa.aspx":   true,
+	"/etd.aspx":   true,
+	"/route.aspx": true,
+	"/sched.aspx": true,
+	"/stn.aspx":   true,
+}
+
+// Values of the X-Cache response header.
+const (
+	cacheHit   = "HIT"
+	cacheMiss  = "MISS"
+	cacheStale = "STALE"
+)
+
+// A proxy serves the same paths as the BART API, and makes its requests with a
+// bart.Client. Responses are cached for the TTL of their cmd, and concurrent
+// requests for the same response share one request to the BART API. If the
+// BART API fails, then a stale response is served, if there is one.
+type proxy struct {
+	client  *bart.Client
+	cache   *cache
+	flights flights
+
+	// ttl is how long a response to the cmd at route is fresh. A zero value
+	// means the response is not cached.
+	ttl func(route, cmd string) time.Duration
+	// timeout is a time limit on requests to the BART API.
+	timeout time.Duration
+	// logf logs failed requests to the BART API.
+	logf func(format string, args ...interface{})
+	now  func() time.Time
+}
+
+func newProxy(client *bart.Client, c *cache, timeout time.Duration, logf func(format string, args ...interface{})) *proxy {
+	return &proxy{
+		client:  client,
+		cache:   c,
+		ttl:     bart.DefaultCacheTTL,
+		timeout: timeout,
+		logf:    logf,
+		now:     time.Now,
+	}
+}
+
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	route := r.URL.Path
+	if !routes[route] {
+		writeError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "")
+		return
+	}
+	query := r.URL.Query()
+	cmd := query.Get("cmd")
+	if cmd == "" {
+		writeError(w, http.StatusBadRequest, "Invalid cmd", "The cmd parameter is required.")
+		return
+	}
+	// The Client sets these, and a caller's key shouldn't split the cache.
+	query.Del("cmd")
+	query.Del("key")
+	query.Del("json")
+	key := route + "?cmd=" + url.QueryEscape(cmd) + "&" + query.Encode()
+
+	entry, cached := p.cache.get(key, p.now())
+	if cached && entry.fresh(p.now()) {
+		p.write(w, entry.body, cacheHit, entry)
+		return
+	}
+
+	body, err := p.flights.do(r.Context(), key, func() ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		defer cancel()
+		body, err := p.client.RequestRaw(ctx, route, cmd, query)
+		if err == nil {
+			if ttl := p.ttl(route, cmd); ttl > 0 {
+				p.cache.set(key, body, p.now(), ttl)
+			}
+		}
+		return body, err
+	})
+	switch {
+	case err == nil:
+		p.write(w, body, cacheMiss, nil)
+	case r.Context().Err() != nil:
+		// The caller has gone away, so there's no one to respond to.
+	case cached && upstreamFailed(err):
+		p.logf("serving stale response to %s: %v", key, err)
+		p.write(w, entry.body, cacheStale, entry)
+	default:
+		p.logf("request to %s failed: %v", key, err)
+		writeUpstreamError(w, err)
+	}
+}
+
+func (p *proxy) write(w http.ResponseWriter, body []byte, status string, entry *cacheEntry) {
+	h := w.Header()
+	h.Set("Content-Type", "application/json; charset=utf-8")
+	h.Set("X-Cache", status)
+	if entry != nil {
+		h.Set("Age", strconv.Itoa(int(entry.age(p.now())/time.Second)))
+	}
+	if status == cacheStale {
+		h.Add("Warning", `110 bartd "Response is Stale"`)
+		h.Add("Warning", `111 bartd "Revalidation Failed"`)
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// upstreamFailed reports whether err means that the BART API is having
+// trouble, rather than there's something wrong with the request. Only those
+// errors are covered up with a stale response.
+func upstreamFailed(err error) bool {
+	var apiErr *bart.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError ||
+			apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// writeUpstreamError passes along an error response from the BART API as-is.
+// Other errors, such as a timeout or a refused connection, are described in
+// the same shape as a BART API error.
+func writeUpstreamError(w http.ResponseWriter, err error) {
+	var apiErr *bart.APIError
+	switch {
+	case errors.As(err, &apiErr) && len(apiErr.Body) > 0:
+		status := apiErr.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		_, _ = w.Write(apiErr.Body)
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, http.StatusText(http.StatusGatewayTimeout), err.Error())
+	default:
+		writeError(w, http.StatusBadGateway, http.StatusText(http.StatusBadGateway), err.Error())
+	}
+}
+
+// writeError responds with an error in the same shape as the BART API, so that
+// clients can handle it the same way.
+func writeError(w http.ResponseWriter, status int, text, details string) {
+	var body struct {
+		Root struct {
+			Message struct {
+				Error struct {
+					Text    string `json:"text"`
+					Details string `json:"details,omitempty"`
+				} `json:"error"`
+			} `json:"message"`
+		} `json:"root"`
+	}
+	body.Root.Message.Error.Text = text
+	body.Root.Message.Error.Details = details
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
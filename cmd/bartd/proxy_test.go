@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rafaelespinoza/bart-go/bart"
+	"github.com/rafaelespinoza/bart-go/bart/barttest"
+)
+
+func TestProxy(t *testing.T) {
+	upstream := barttest.NewServer()
+	defer upstream.Close()
+
+	now := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	setNow := func(val time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = val
+	}
+
+	client := upstream.Client(&bart.Config{Key: "bartd-key"})
+	p := newProxy(client, newCache(100, time.Hour), time.Second, t.Logf)
+	p.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	server := httptest.NewServer(p)
+	defer server.Close()
+
+	get := func(t *testing.T, path string) (*http.Response, []byte) {
+		t.Helper()
+		res, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, body
+	}
+	expectStatus := func(t *testing.T, res *http.Response, code int, xCache string) {
+		t.Helper()
+		if res.StatusCode != code {
+			t.Errorf("wrong status code; got %d, expected %d", res.StatusCode, code)
+		}
+		if got := res.Header.Get("X-Cache"); got != xCache {
+			t.Errorf("wrong X-Cache; got %q, expected %q", got, xCache)
+		}
+	}
+
+	t.Run("cache", func(t *testing.T) {
+		upstream.Reset()
+		res, body := get(t, "/etd.aspx?cmd=etd&orig=mcar&json=y&key=caller-key")
+		expectStatus(t, res, http.StatusOK, cacheMiss)
+		var etd bart.EstimatesResponse
+		if err := json.Unmarshal(body, &etd); err != nil {
+			t.Fatal(err)
+		}
+		if len(etd.Root.Data) != 1 || etd.Root.Data[0].Abbr != "MCAR" {
+			t.Errorf("expected estimates for MCAR, got %+v", etd.Root.Data)
+		}
+		upstream.AssertRequested(t, "etd", map[string]string{"orig": "mcar", "key": "bartd-key"})
+
+		// The caller's key isn't part of the cache key.
+		res, _ = get(t, "/etd.aspx?cmd=etd&orig=mcar&key=other-key")
+		expectStatus(t, res, http.StatusOK, cacheHit)
+		upstream.AssertRequestCount(t, "etd", 1)
+
+		res, _ = get(t, "/etd.aspx?cmd=etd&orig=12th")
+		expectStatus(t, res, http.StatusOK, cacheMiss)
+		upstream.AssertRequestCount(t, "etd", 2)
+
+		// The TTL for etd is 15 seconds.
+		setNow(now.Add(16 * time.Second))
+		res, _ = get(t, "/etd.aspx?cmd=etd&orig=mcar")
+		expectStatus(t, res, http.StatusOK, cacheMiss)
+		upstream.AssertRequestCount(t, "etd", 3)
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		upstream.Reset()
+		res, _ := get(t, "/stn.aspx?cmd=stns")
+		expectStatus(t, res, http.StatusOK, cacheMiss)
+
+		// The TTL for stns is 6 hours.
+		setNow(now.Add(6*time.Hour + 30*time.Minute))
+		upstream.FailNext("stns", barttest.Failure{Shape: barttest.ErrorJSONObject, StatusCode: http.StatusServiceUnavailable, Text: "Service unavailable"})
+		res, body := get(t, "/stn.aspx?cmd=stns")
+		expectStatus(t, res, http.StatusOK, cacheStale)
+		if len(res.Header.Values("Warning")) == 0 {
+			t.Error("expected a Warning header")
+		}
+		if got := res.Header.Get("Age"); got != "23400" {
+			t.Errorf("wrong Age; got %q, expected %q", got, "23400")
+		}
+		var stns bart.StationsResponse
+		if err := json.Unmarshal(body, &stns); err != nil {
+			t.Fatal(err)
+		}
+		if len(stns.Root.Data.List) == 0 {
+			t.Error("expected the stale list of stations")
+		}
+
+		// Past the max-stale, the error is passed along.
+		setNow(now.Add(2 * time.Hour))
+		upstream.FailNext("stns", barttest.Failure{Shape: barttest.ErrorJSONObject, StatusCode: http.StatusServiceUnavailable, Text: "Service unavailable"})
+		res, _ = get(t, "/stn.aspx?cmd=stns")
+		expectStatus(t, res, http.StatusServiceUnavailable, "")
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		// An error about the request itself is passed along as-is, rather than
+		// covered up with a stale response.
+		upstream.Reset()
+		res, _ := get(t, "/sched.aspx?cmd=fare&orig=12th&dest=embr")
+		expectStatus(t, res, http.StatusOK, cacheMiss)
+
+		setNow(now.Add(25 * time.Hour))
+		upstream.FailNext("fare", barttest.Failure{Shape: barttest.ErrorJSONObject, StatusCode: http.StatusBadRequest, Text: "Invalid orig"})
+		res, body := get(t, "/sched.aspx?cmd=fare&orig=12th&dest=embr")
+		expectStatus(t, res, http.StatusBadRequest, "")
+		var apiErr struct {
+			Root struct {
+				Message struct {
+					Error struct{ Text string }
+				}
+			}
+		}
+		if err := json.Unmarshal(body, &apiErr); err != nil {
+			t.Fatal(err)
+		}
+		if got := apiErr.Root.Message.Error.Text; got != "Invalid orig" {
+			t.Errorf("wrong error text; got %q, expected %q", got, "Invalid orig")
+		}
+	})
+
+	t.Run("collapse", func(t *testing.T) {
+		upstream.Reset()
+		upstream.SetLatency(50 * time.Millisecond)
+		defer upstream.SetLatency(0)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := http.Get(server.URL + "/bsa.aspx?cmd=count")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				res.Body.Close()
+				if res.StatusCode != http.StatusOK {
+					t.Errorf("wrong status code; got %d, expected %d", res.StatusCode, http.StatusOK)
+				}
+			}()
+		}
+		wg.Wait()
+		upstream.AssertRequestCount(t, "count", 1)
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		tests := []struct {
+			method string
+			path   string
+			code   int
+		}{
+			{method: http.MethodGet, path: "/nope.aspx?cmd=etd", code: http.StatusNotFound},
+			{method: http.MethodGet, path: "/etd.aspx", code: http.StatusBadRequest},
+			{method: http.MethodPost, path: "/etd.aspx?cmd=etd", code: http.StatusMethodNotAllowed},
+		}
+		for _, test := range tests {
+			req, err := http.NewRequest(test.method, server.URL+test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != test.code {
+				t.Errorf("%s %s; wrong status code; got %d, expected %d", test.method, test.path, res.StatusCode, test.code)
+			}
+		}
+
+		// The errors are in the same shape as the BART API.
+		client := bart.NewClient(&bart.Config{BaseURL: server.URL})
+		_, err := client.RequestRaw(context.Background(), "/etd.aspx", "", nil)
+		if !errors.Is(err, bart.ErrInvalidCmd) {
+			t.Errorf("expected %v, got %v", bart.ErrInvalidCmd, err)
+		}
+	})
+}